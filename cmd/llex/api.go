@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+)
+
+// The number of entries searches return if no limit is given, and the most they
+// can be asked to return.
+const (
+	defaultAPILimit = 20
+	maxAPILimit     = 500
+)
+
+// An error returned by the API, as {"error": "..."}.
+type apiError struct {
+	Status  int    `json:"-"`
+	Message string `json:"error"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(v)
+}
+
+// Read the limit parameter of a search.
+func apiLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultAPILimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxAPILimit {
+		return 0, &apiError{http.StatusBadRequest, fmt.Sprintf("limit must be a number from 1 to %d", maxAPILimit)}
+	}
+	return limit, nil
+}
+
+// Read a parameter that must be given.
+func apiRequired(r *http.Request, name string) (string, error) {
+	value := strings.TrimSpace(r.URL.Query().Get(name))
+	if value == "" {
+		return "", &apiError{http.StatusBadRequest, fmt.Sprintf("the %s parameter is required", name)}
+	}
+	return value, nil
+}
+
+// Keep only the entries with the part of speech in the pos parameter, if it is
+// given.
+func filterPOS(r *http.Request, entries []*llex.Entry) []*llex.Entry {
+	pos := strings.TrimSpace(r.URL.Query().Get("pos"))
+	if pos == "" {
+		return entries
+	}
+
+	filtered := make([]*llex.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if strings.EqualFold(entry.POS, pos) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// An API endpoint, answering with the value to send as JSON.
+type apiEndpoint func(lookup *llex.Lookup, r *http.Request) (any, error)
+
+// Serve the JSON API for the lexicon current returns. Entries are returned in
+// the same form as in lexicon files, and lists of them in alphabetical order.
+//
+//	GET /api/entries              every entry, or those with ?pos=
+//	GET /api/entries/{id}         the entry with an ID
+//	GET /api/lookup?word=         the entries with a headword or variant
+//	GET /api/search?q=            headwords starting with or close to q
+//	GET /api/reverse?q=           entries whose glosses or definitions have q
+//	GET /api/random               a random entry, optionally with ?pos=
+//
+// Searches take a limit parameter, and all lists can be filtered with pos.
+func apiHandler(current func() (*llex.Lookup, error)) http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, endpoint apiEndpoint) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			// The API is read-only, so any page may use it.
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+
+			lookup, err := current()
+			if err != nil {
+				writeJSON(w, http.StatusServiceUnavailable, &apiError{Message: "the lexicon could not be loaded: " + err.Error()})
+				return
+			}
+
+			v, err := endpoint(lookup, r)
+			if err != nil {
+				apiErr, ok := err.(*apiError)
+				if !ok {
+					apiErr = &apiError{http.StatusInternalServerError, err.Error()}
+				}
+				writeJSON(w, apiErr.Status, apiErr)
+				return
+			}
+			writeJSON(w, http.StatusOK, v)
+		})
+	}
+
+	handle("GET /api/entries", func(lookup *llex.Lookup, r *http.Request) (any, error) {
+		return filterPOS(r, lookup.Entries(nil)), nil
+	})
+
+	handle("GET /api/entries/{id}", func(lookup *llex.Lookup, r *http.Request) (any, error) {
+		entry, ok := lookup.ByID(r.PathValue("id"))
+		if !ok {
+			return nil, &apiError{http.StatusNotFound, fmt.Sprintf("no entry has the ID '%s'", r.PathValue("id"))}
+		}
+		return entry, nil
+	})
+
+	handle("GET /api/lookup", func(lookup *llex.Lookup, r *http.Request) (any, error) {
+		word, err := apiRequired(r, "word")
+		if err != nil {
+			return nil, err
+		}
+		return filterPOS(r, lookup.Headword(word)), nil
+	})
+
+	handle("GET /api/search", func(lookup *llex.Lookup, r *http.Request) (any, error) {
+		query, err := apiRequired(r, "q")
+		if err != nil {
+			return nil, err
+		}
+		limit, err := apiLimit(r)
+		if err != nil {
+			return nil, err
+		}
+		// Filter before limiting, so that the limit counts matching entries.
+		entries := filterPOS(r, lookup.Search(query, 0))
+		return entries[:min(limit, len(entries))], nil
+	})
+
+	handle("GET /api/reverse", func(lookup *llex.Lookup, r *http.Request) (any, error) {
+		query, err := apiRequired(r, "q")
+		if err != nil {
+			return nil, err
+		}
+		limit, err := apiLimit(r)
+		if err != nil {
+			return nil, err
+		}
+		entries := filterPOS(r, lookup.Reverse(query, 0))
+		return entries[:min(limit, len(entries))], nil
+	})
+
+	handle("GET /api/random", func(lookup *llex.Lookup, r *http.Request) (any, error) {
+		pos := strings.TrimSpace(r.URL.Query().Get("pos"))
+		entry := lookup.Random(func(entry *llex.Entry) bool {
+			return pos == "" || strings.EqualFold(entry.POS, pos)
+		})
+		if entry == nil {
+			return nil, &apiError{http.StatusNotFound, "no entries match"}
+		}
+		return entry, nil
+	})
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, &apiError{Message: "no such endpoint; see /api/entries, /api/lookup, /api/search, /api/reverse and /api/random"})
+	})
+
+	return mux
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+)
+
+func newTestAPI(t *testing.T) *httptest.Server {
+	dict := &llex.Dictionary{Entries: []*llex.Entry{
+		{ID: "bat-1", Word: "bat", Homograph: 1, POS: "n", Senses: []*llex.Sense{
+			{Glosses: []string{"flying mammal"}},
+		}},
+		{ID: "bat-2", Word: "bat", Homograph: 2, POS: "v", Senses: []*llex.Sense{
+			{Glosses: []string{"hit"}},
+		}},
+		{ID: "kanata", Word: "kanata", POS: "n", Variants: []string{"kanatta"}, Senses: []*llex.Sense{
+			{Definitions: []*llex.Definition{{Text: "The mouth of a river."}}},
+		}},
+		{ID: "tabat", Word: "tabat", POS: "n", Senses: []*llex.Sense{
+			{Glosses: []string{"club"}, Definitions: []*llex.Definition{{Text: "A heavy stick used to hit things."}}},
+		}},
+	}}
+	llex.AssignEntryIDs(dict)
+
+	server := httptest.NewServer(apiHandler(func() (*llex.Lookup, error) {
+		return llex.NewLookup(dict), nil
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Get a path from the API, check the status, and decode the body into v.
+func getAPI(t *testing.T, server *httptest.Server, path string, status int, v any) {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		t.Fatalf("GET %s: got status %d, want %d", path, resp.StatusCode, status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+}
+
+func entryIDs(entries []*llex.Entry) []string {
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func TestAPIEntryLists(t *testing.T) {
+	server := newTestAPI(t)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/api/entries", []string{"bat-1", "bat-2", "kanata", "tabat"}},
+		{"/api/entries?pos=v", []string{"bat-2"}},
+		{"/api/entries?pos=N", []string{"bat-1", "kanata", "tabat"}},
+		{"/api/lookup?word=bat", []string{"bat-1", "bat-2"}},
+		{"/api/lookup?word=bat&pos=v", []string{"bat-2"}},
+		{"/api/lookup?word=kanatta", []string{"kanata"}},
+		{"/api/lookup?word=ba", nil},
+		// A prefix of a headword.
+		{"/api/search?q=kan", []string{"kanata"}},
+		// A misspelling, which is close to the headword.
+		{"/api/search?q=tabet", []string{"tabat"}},
+		{"/api/search?q=kanata", []string{"kanata"}},
+		// A misspelling of a variant.
+		{"/api/search?q=kanotta", []string{"kanata"}},
+		{"/api/search?q=ba", []string{"bat-1", "bat-2"}},
+		{"/api/search?q=ba&limit=1", []string{"bat-1"}},
+		{"/api/search?q=ba&pos=v", []string{"bat-2"}},
+		// Glosses come before words of definitions.
+		{"/api/reverse?q=hit", []string{"bat-2", "tabat"}},
+		{"/api/reverse?q=river", []string{"kanata"}},
+		{"/api/reverse?q=hit&pos=n", []string{"tabat"}},
+	}
+
+	for _, test := range tests {
+		var entries []*llex.Entry
+		getAPI(t, server, test.path, http.StatusOK, &entries)
+		if got := entryIDs(entries); !slices.Equal(got, test.want) {
+			t.Errorf("GET %s = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestAPIEntry(t *testing.T) {
+	server := newTestAPI(t)
+
+	var entry llex.Entry
+	getAPI(t, server, "/api/entries/bat-2", http.StatusOK, &entry)
+	if entry.Word != "bat" || entry.Homograph != 2 {
+		t.Errorf("got %s %d, want bat 2", entry.Word, entry.Homograph)
+	}
+
+	for i := 0; i < 20; i++ {
+		var random llex.Entry
+		getAPI(t, server, "/api/random?pos=v", http.StatusOK, &random)
+		if random.ID != "bat-2" {
+			t.Fatalf("/api/random?pos=v returned %s, which is not a verb", random.ID)
+		}
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	server := newTestAPI(t)
+
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/api/entries/nowhere", http.StatusNotFound, "no entry has the ID 'nowhere'"},
+		{"/api/random?pos=adj", http.StatusNotFound, "no entries match"},
+		{"/api/lookup", http.StatusBadRequest, "the word parameter is required"},
+		{"/api/search?q=+", http.StatusBadRequest, "the q parameter is required"},
+		{"/api/reverse", http.StatusBadRequest, "the q parameter is required"},
+		{"/api/search?q=bat&limit=0", http.StatusBadRequest, "limit must be a number from 1 to 500"},
+		{"/api/search?q=bat&limit=501", http.StatusBadRequest, "limit must be a number from 1 to 500"},
+		{"/api/reverse?q=hit&limit=ten", http.StatusBadRequest, "limit must be a number from 1 to 500"},
+	}
+
+	for _, test := range tests {
+		var body map[string]string
+		getAPI(t, server, test.path, test.status, &body)
+		if body["error"] != test.want {
+			t.Errorf("GET %s: got error %q, want %q", test.path, body["error"], test.want)
+		}
+	}
+
+	var body map[string]string
+	getAPI(t, server, "/api/nothing", http.StatusNotFound, &body)
+	if body["error"] == "" {
+		t.Errorf("GET /api/nothing: got no error message")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+// The path of the project configuration given with --config, or the one found
+// from the working directory. It returns "" if there is none.
+func configPath(cCtx *cli.Context) (string, error) {
+	if path := cCtx.String("config"); path != "" {
+		return path, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return llex.FindConfig(wd)
+}
+
+// Load the project configuration given with --config, or the one found from the
+// working directory. It returns nil if there is none.
+func loadConfig(cCtx *cli.Context) (*llex.Config, error) {
+	path, err := configPath(cCtx)
+	if err != nil || path == "" {
+		return nil, err
+	}
+
+	return llex.LoadConfig(path)
+}
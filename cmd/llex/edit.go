@@ -0,0 +1,355 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+// Edits a lexicon file through the browser. Every change reads the file again and
+// writes it back in canonical form, so the editor keeps no copy of the lexicon
+// that could go stale.
+//
+// Changes must send the ETag of the version of the file they were made to in an
+// If-Match header. If the file has been changed since, by another editor or by
+// hand, the change is refused rather than overwriting it. Requiring the header
+// also means that other websites cannot change the file, since browsers do not
+// let them send it to a different origin without its permission. Requests for
+// any host other than the editor's own address or localhost are refused, so that
+// a website cannot become the same origin by pointing its own domain at the
+// editor's address (DNS rebinding).
+type lexiconEditor struct {
+	path string
+	addr string     // The address the editor listens on.
+	mu   sync.Mutex // Held while a change is made, so that changes do not overwrite each other.
+}
+
+// Whether a request's Host header names the editor, either by the address it
+// listens on or as localhost.
+func (e *lexiconEditor) allowedHost(host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	hostname = strings.Trim(hostname, "[]")
+
+	if listen, _, err := net.SplitHostPort(e.addr); err == nil && listen != "" && strings.EqualFold(hostname, listen) {
+		return true
+	}
+	if strings.EqualFold(hostname, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Identify a version of a lexicon file.
+func lexiconETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// The lexicon as the editor shows it.
+type editorLexicon struct {
+	LanguageName string            `json:"languageName"`
+	Entries      []*llex.Entry     `json:"entries"`
+	Issues       []*llex.LintIssue `json:"issues"`
+}
+
+// The result of a change.
+type editorResult struct {
+	Entry  *llex.Entry       `json:"entry,omitempty"` // The entry as it was saved.
+	Issues []*llex.LintIssue `json:"issues"`          // Warnings introduced by the change.
+}
+
+// Read the lexicon file, checking that it is the version a change was made to.
+func (e *lexiconEditor) read(r *http.Request) (*llex.Dictionary, error) {
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return nil, err
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil, &apiError{http.StatusPreconditionRequired, "changes must give the version of the lexicon they were made to in an If-Match header"}
+	}
+	if ifMatch != lexiconETag(data) {
+		return nil, &apiError{http.StatusPreconditionFailed, "the lexicon has been changed since it was loaded; reload it and make the change again"}
+	}
+
+	dict, err := readForFormatting(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.path, err)
+	}
+	return dict, nil
+}
+
+// Write the lexicon file in canonical form, returning the ETag of what was written.
+func (e *lexiconEditor) write(dict *llex.Dictionary) (string, error) {
+	formatted, err := llex.FormatDictionary(dict)
+	if err != nil {
+		return "", err
+	}
+	if err := llex.WriteFileAtomic(e.path, formatted); err != nil {
+		return "", err
+	}
+	return lexiconETag(formatted), nil
+}
+
+// Read an entry from a request body, rejecting fields that llex does not know
+// about.
+func readEntry(r *http.Request) (*llex.Entry, error) {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	var entry llex.Entry
+	if err := decoder.Decode(&entry); err != nil {
+		return nil, &apiError{http.StatusBadRequest, "invalid entry: " + err.Error()}
+	}
+	return &entry, nil
+}
+
+// Identify a lint issue of a dictionary. The entry the issue is in is named by
+// its ID rather than its index, since adding and deleting entries moves the
+// entries after them.
+func lintIssueKey(dict *llex.Dictionary, issue *llex.LintIssue) string {
+	path := issue.Path
+	if rest, ok := strings.CutPrefix(path, "entries["); ok {
+		index, tail, _ := strings.Cut(rest, "]")
+		if i, err := strconv.Atoi(index); err == nil && i < len(dict.Entries) {
+			path = dict.Entries[i].ID + tail
+		}
+	}
+	return issue.Rule + "\x00" + path + "\x00" + issue.Message
+}
+
+// The keys of the issues in a report, to compare with the issues found after a
+// change.
+func lintIssueKeys(dict *llex.Dictionary, report *llex.LintReport) map[string]bool {
+	keys := make(map[string]bool)
+	for _, issue := range report.Issues {
+		keys[lintIssueKey(dict, issue)] = true
+	}
+	return keys
+}
+
+// The issues in after that were not there before a change, so that changes are
+// only held responsible for the problems they cause.
+func newLintIssues(before map[string]bool, dict *llex.Dictionary, after *llex.LintReport) []*llex.LintIssue {
+	issues := make([]*llex.LintIssue, 0)
+	for _, issue := range after.Issues {
+		if !before[lintIssueKey(dict, issue)] {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// Make a change to the lexicon and save it, unless it introduces errors found by
+// the linter. Warnings do not stop the change, but are returned with it, such as
+// the relations left dangling when an entry is deleted.
+func (e *lexiconEditor) change(w http.ResponseWriter, r *http.Request, apply func(dict *llex.Dictionary) (*llex.Entry, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	respondError := func(err error) {
+		apiErr, ok := err.(*apiError)
+		if !ok {
+			apiErr = &apiError{http.StatusInternalServerError, err.Error()}
+		}
+		writeJSON(w, apiErr.Status, apiErr)
+	}
+
+	dict, err := e.read(r)
+	if err != nil {
+		respondError(err)
+		return
+	}
+
+	lintParams := &llex.LintParams{}
+	report, err := llex.Lint(dict, lintParams)
+	if err != nil {
+		respondError(err)
+		return
+	}
+	// Keyed before the change, while the issues' paths still refer to the
+	// entries they were found in.
+	before := lintIssueKeys(dict, report)
+
+	entry, err := apply(dict)
+	if err != nil {
+		respondError(err)
+		return
+	}
+
+	if _, err := llex.NormalizeDictionary(dict); err != nil {
+		respondError(err)
+		return
+	}
+	llex.AssignEntryIDs(dict)
+
+	after, err := llex.Lint(dict, lintParams)
+	if err != nil {
+		respondError(err)
+		return
+	}
+	result := &editorResult{Entry: entry, Issues: newLintIssues(before, dict, after)}
+
+	for _, issue := range result.Issues {
+		if issue.Severity == llex.LintError {
+			writeJSON(w, http.StatusUnprocessableEntity, result)
+			return
+		}
+	}
+
+	etag, err := e.write(dict)
+	if err != nil {
+		respondError(err)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// The index of the entry with an ID among the main entries of a dictionary.
+func findEntry(dict *llex.Dictionary, id string) (int, error) {
+	for i, entry := range dict.Entries {
+		if entry.ID == id {
+			return i, nil
+		}
+	}
+	return -1, &apiError{http.StatusNotFound, fmt.Sprintf("no entry has the ID '%s'", id)}
+}
+
+func (e *lexiconEditor) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(editorPage))
+	})
+
+	mux.HandleFunc("GET /api/lexicon", func(w http.ResponseWriter, r *http.Request) {
+		data, err := os.ReadFile(e.path)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, &apiError{Message: err.Error()})
+			return
+		}
+		dict, err := readForFormatting(data)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, &apiError{Message: fmt.Sprintf("%s: %s", e.path, err)})
+			return
+		}
+		report, err := llex.Lint(dict, &llex.LintParams{})
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, &apiError{Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("ETag", lexiconETag(data))
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, &editorLexicon{
+			LanguageName: dict.LanguageName,
+			Entries:      dict.Entries,
+			Issues:       report.Issues,
+		})
+	})
+
+	mux.HandleFunc("POST /api/entries", func(w http.ResponseWriter, r *http.Request) {
+		entry, err := readEntry(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, err)
+			return
+		}
+		// New entries are given an ID from their headword.
+		entry.ID = ""
+
+		e.change(w, r, func(dict *llex.Dictionary) (*llex.Entry, error) {
+			dict.Entries = append(dict.Entries, entry)
+			return entry, nil
+		})
+	})
+
+	mux.HandleFunc("PUT /api/entries/{id}", func(w http.ResponseWriter, r *http.Request) {
+		entry, err := readEntry(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, err)
+			return
+		}
+		// Keep the ID, so that relations to the entry still find it.
+		entry.ID = r.PathValue("id")
+
+		e.change(w, r, func(dict *llex.Dictionary) (*llex.Entry, error) {
+			i, err := findEntry(dict, entry.ID)
+			if err != nil {
+				return nil, err
+			}
+			dict.Entries[i] = entry
+			return entry, nil
+		})
+	})
+
+	mux.HandleFunc("DELETE /api/entries/{id}", func(w http.ResponseWriter, r *http.Request) {
+		e.change(w, r, func(dict *llex.Dictionary) (*llex.Entry, error) {
+			i, err := findEntry(dict, r.PathValue("id"))
+			if err != nil {
+				return nil, err
+			}
+			dict.Entries = append(dict.Entries[:i], dict.Entries[i+1:]...)
+			return nil, nil
+		})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !e.allowedHost(r.Host) {
+			writeJSON(w, http.StatusForbidden, &apiError{Message: fmt.Sprintf("the editor only answers requests for %s or localhost, not %s", e.addr, r.Host)})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Edit a lexicon file in the browser.
+func cmdEdit(cCtx *cli.Context) error {
+	path := cCtx.Args().First()
+	if path == "" {
+		config, err := loadConfig(cCtx)
+		if err != nil {
+			return err
+		}
+		if config != nil {
+			path = config.Lexicon
+		}
+	}
+	if path == "" {
+		return errors.New("no lexicon given; give a file or set lexicon in " + llex.ConfigFileName)
+	}
+	if cCtx.NArg() > 1 {
+		return errors.New("only one lexicon can be edited at a time")
+	}
+
+	// Check that the lexicon can be edited before starting.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := readForFormatting(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	addr := cCtx.String("addr")
+	editor := &lexiconEditor{path: path, addr: addr}
+	fmt.Fprintf(os.Stderr, "Editing %s at http://%s/\n", path, addr)
+	return http.ListenAndServe(addr, editor.handler())
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+)
+
+// Start an editor for a new lexicon file, returning the server and the file.
+func newTestEditor(t *testing.T) (*httptest.Server, string) {
+	path := filepath.Join(t.TempDir(), "lexicon.json")
+	dict := &llex.Dictionary{LanguageName: "Test", Entries: []*llex.Entry{
+		{Word: "bat", POS: "n", Senses: []*llex.Sense{{Glosses: []string{"club"}}}},
+		{Word: "tabat", POS: "n", Senses: []*llex.Sense{{Glosses: []string{"stick"}}}, Relations: []*llex.Relation{
+			{Type: llex.RelationSeeAlso, Target: "bat"},
+		}},
+		{Word: "zaba", POS: "v"},
+	}}
+	if err := llex.WriteDictionary(path, dict); err != nil {
+		t.Fatal(err)
+	}
+
+	editor := &lexiconEditor{path: path}
+	server := httptest.NewServer(editor.handler())
+	t.Cleanup(server.Close)
+	return server, path
+}
+
+// Make a request to the editor, decoding the response body into v.
+func editorRequest(t *testing.T, server *httptest.Server, method string, path string, etag string, body any, v any) *http.Response {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+func TestEditorETagMatchesFile(t *testing.T) {
+	server, path := newTestEditor(t)
+
+	var lexicon editorLexicon
+	resp := editorRequest(t, server, "GET", "/api/lexicon", "", nil, &lexicon)
+	etag := resp.Header.Get("ETag")
+
+	entry := &llex.Entry{Word: "kanata", POS: "n", Senses: []*llex.Sense{{Glosses: []string{"river mouth"}}}}
+	var result editorResult
+	resp = editorRequest(t, server, "POST", "/api/entries", etag, entry, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /api/entries: got status %d", resp.StatusCode)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get("ETag"); got != lexiconETag(data) {
+		t.Errorf("got ETag %s, but the file's is %s", got, lexiconETag(data))
+	}
+}
+
+func TestEditorDeleteReportsDanglingRelations(t *testing.T) {
+	server, _ := newTestEditor(t)
+
+	var lexicon editorLexicon
+	resp := editorRequest(t, server, "GET", "/api/lexicon", "", nil, &lexicon)
+
+	var result editorResult
+	resp = editorRequest(t, server, "DELETE", "/api/entries/bat", resp.Header.Get("ETag"), nil, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE /api/entries/bat: got status %d", resp.StatusCode)
+	}
+
+	// Only the relation from tabat is new. Zaba's missing definitions have moved
+	// to another index, but are not new.
+	if len(result.Issues) != 1 || result.Issues[0].Rule != "dangling-relation" || result.Issues[0].Word != "tabat" {
+		t.Errorf("got issues %+v, want a dangling relation from tabat", result.Issues)
+	}
+}
+
+func TestEditorAllowedHost(t *testing.T) {
+	editor := &lexiconEditor{addr: "lexicon.lan:8080"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"localhost:8080", true},
+		{"LOCALHOST", true},
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{"lexicon.lan:8080", true},
+		{"attacker.example:8080", false},
+		{"localhost.attacker.example", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := editor.allowedHost(test.host); got != test.want {
+			t.Errorf("allowedHost(%q) = %v, want %v", test.host, got, test.want)
+		}
+	}
+}
+
+func TestEditorRejectsOtherHosts(t *testing.T) {
+	server, _ := newTestEditor(t)
+
+	req, err := http.NewRequest("GET", server.URL+"/api/lexicon", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As sent by a browser on a website whose domain now points at the editor.
+	req.Host = "attacker.example:8080"
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,475 @@
+package main
+
+// The page of the lexicon editor. It talks to the editor through the endpoints
+// in lexiconEditor.handler.
+var editorPage = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<meta name="generator" content="lemurian-lexicon-manager">
+	<title>llex editor</title>
+	<style>
+		* { box-sizing: border-box; }
+		body { margin: 0; font-family: sans-serif; background: #f4f4f4; color: #222; height: 100vh; display: flex; flex-direction: column; }
+		header { display: flex; gap: 1em; align-items: center; padding: 0.5em 1em; background: #2d3a4a; color: #fff; }
+		header h1 { font-size: 1.1em; margin: 0; }
+		header input { flex: 1; max-width: 20em; padding: 0.3em; }
+		#status { margin-left: auto; font-size: 0.9em; }
+		main { flex: 1; display: flex; min-height: 0; }
+		#entries { width: 18em; overflow-y: auto; margin: 0; padding: 0; list-style: none; background: #fff; border-right: 1px solid #ccc; }
+		#entries li { padding: 0.4em 0.8em; cursor: pointer; border-bottom: 1px solid #eee; }
+		#entries li:hover { background: #eef3f8; }
+		#entries li.selected { background: #d5e3f2; }
+		#entries .pos { color: #666; font-style: italic; margin-left: 0.4em; }
+		#entries .badge { float: right; font-size: 0.8em; padding: 0 0.4em; border-radius: 0.6em; background: #e0a800; color: #fff; }
+		#entries .badge.error { background: #c0392b; }
+		#editor { flex: 1; overflow-y: auto; padding: 1em 2em; }
+		#editor[hidden] { display: none; }
+		label { display: block; margin-top: 0.8em; font-weight: bold; font-size: 0.9em; }
+		label small { font-weight: normal; color: #666; }
+		input[type=text], input[type=number], textarea { width: 100%; padding: 0.35em; font: inherit; }
+		textarea { min-height: 3.5em; }
+		.sense { border: 1px solid #ccc; background: #fff; padding: 0.5em 1em 1em; margin-top: 0.8em; }
+		.sense h3 { margin: 0.3em 0; font-size: 1em; display: flex; justify-content: space-between; }
+		.buttons { margin-top: 1.2em; display: flex; gap: 0.5em; }
+		button { padding: 0.4em 1em; cursor: pointer; }
+		button.danger { color: #c0392b; }
+		#json { font-family: monospace; min-height: 20em; }
+		#issues { list-style: none; padding: 0; }
+		#issues li { padding: 0.3em 0.6em; margin-top: 0.3em; border-left: 4px solid #e0a800; background: #fff8e1; }
+		#issues li.error { border-color: #c0392b; background: #fdecea; }
+		#message { margin-top: 0.8em; }
+		#message.error { color: #c0392b; }
+		details { margin-top: 1.2em; }
+	</style>
+</head>
+<body>
+	<header>
+		<h1 id="title">llex editor</h1>
+		<input type="search" id="search" placeholder="Search headwords and meanings">
+		<button id="new">New entry</button>
+		<span id="status"></span>
+	</header>
+	<main>
+		<ul id="entries"></ul>
+		<form id="editor" hidden autocomplete="off">
+			<h2 id="heading"></h2>
+			<label>Headword <input type="text" id="word" required></label>
+			<label>Homograph number <small>(leave empty to number homographs automatically)</small> <input type="number" id="homograph" min="1"></label>
+			<label>Part of speech <input type="text" id="pos" list="pos-list"></label>
+			<datalist id="pos-list"></datalist>
+			<label>Pronunciations <small>(separated by semicolons)</small> <input type="text" id="pronunciations"></label>
+			<label>Variants <small>(separated by semicolons)</small> <input type="text" id="variants"></label>
+			<div id="senses"></div>
+			<div class="buttons"><button type="button" id="add-sense">Add sense</button></div>
+			<label>Etymology <textarea id="etymology"></textarea></label>
+			<label>Usage notes <small>(one per line)</small> <textarea id="usage-notes"></textarea></label>
+			<details id="advanced">
+				<summary>Edit as JSON</summary>
+				<p>Every field of the entry, including examples, relations and subentries.</p>
+				<textarea id="json" spellcheck="false"></textarea>
+				<div class="buttons"><button type="button" id="apply-json">Apply JSON</button></div>
+			</details>
+			<div class="buttons">
+				<button type="submit">Save</button>
+				<button type="button" id="cancel">Discard changes</button>
+				<button type="button" id="delete" class="danger">Delete entry</button>
+			</div>
+			<div id="message"></div>
+			<ul id="issues"></ul>
+		</form>
+	</main>
+	<script>
+	(function () {
+		"use strict";
+
+		var state = { entries: [], issues: [], etag: null, current: null, isNew: false };
+
+		function $(id) {
+			return document.getElementById(id);
+		}
+
+		function fold(s) {
+			return (s || "").toLowerCase().normalize("NFD").replace(/\p{Mn}/gu, "");
+		}
+
+		function element(tag, className, text) {
+			var el = document.createElement(tag);
+			if (className) {
+				el.className = className;
+			}
+			if (text) {
+				el.textContent = text;
+			}
+			return el;
+		}
+
+		function splitList(text, separator) {
+			return text.split(separator).map(function (s) {
+				return s.trim();
+			}).filter(function (s) {
+				return s !== "";
+			});
+		}
+
+		function setStatus(text) {
+			$("status").textContent = text;
+		}
+
+		function showMessage(text, isError) {
+			$("message").textContent = text;
+			$("message").className = isError ? "error" : "";
+		}
+
+		function showIssues(issues) {
+			var list = $("issues");
+			list.textContent = "";
+			(issues || []).forEach(function (issue) {
+				list.appendChild(element("li", issue.severity, issue.severity + ": " + issue.message + " (" + issue.rule + ", " + issue.path + ")"));
+			});
+		}
+
+		function request(method, url, body) {
+			var headers = {};
+			if (method !== "GET") {
+				headers["If-Match"] = state.etag;
+			}
+			if (body !== undefined) {
+				headers["Content-Type"] = "application/json";
+			}
+			return fetch(url, {
+				method: method,
+				headers: headers,
+				body: body === undefined ? undefined : JSON.stringify(body)
+			}).then(function (response) {
+				return response.json().then(function (data) {
+					return { status: response.status, etag: response.headers.get("ETag"), data: data };
+				});
+			});
+		}
+
+		// The issues of each main entry, by its index in the file.
+		function issuesByEntry() {
+			var byEntry = {};
+			state.issues.forEach(function (issue) {
+				var match = /^entries\[(\d+)\]/.exec(issue.path);
+				if (match) {
+					(byEntry[match[1]] = byEntry[match[1]] || []).push(issue);
+				}
+			});
+			return byEntry;
+		}
+
+		function matches(entry, query) {
+			if (query === "") {
+				return true;
+			}
+			var text = [entry.word].concat(entry.variants || []);
+			(entry.senses || []).forEach(function (sense) {
+				text = text.concat(sense.glosses || []);
+				(sense.definitions || []).forEach(function (def) {
+					text.push(def.text);
+				});
+			});
+			return text.some(function (s) {
+				return fold(s).indexOf(query) !== -1;
+			});
+		}
+
+		function renderList() {
+			var list = $("entries");
+			var query = fold($("search").value.trim());
+			var byEntry = issuesByEntry();
+			list.textContent = "";
+
+			state.entries.forEach(function (entry, i) {
+				if (!matches(entry, query)) {
+					return;
+				}
+				var item = element("li", state.current && !state.isNew && state.current.id === entry.id ? "selected" : "");
+				item.appendChild(element("span", "word", entry.word || "(no headword)"));
+				if (entry.homograph) {
+					item.appendChild(element("sup", "", String(entry.homograph)));
+				}
+				if (entry.partOfSpeech) {
+					item.appendChild(element("span", "pos", entry.partOfSpeech));
+				}
+				var issues = byEntry[i];
+				if (issues) {
+					var hasError = issues.some(function (issue) {
+						return issue.severity === "error";
+					});
+					var badge = element("span", hasError ? "badge error" : "badge", String(issues.length));
+					badge.title = issues.map(function (issue) {
+						return issue.message;
+					}).join("\n");
+					item.appendChild(badge);
+				}
+				item.addEventListener("click", function () {
+					select(entry.id);
+				});
+				list.appendChild(item);
+			});
+
+			var posList = $("pos-list");
+			posList.textContent = "";
+			var seen = {};
+			state.entries.forEach(function (entry) {
+				if (entry.partOfSpeech && !seen[entry.partOfSpeech]) {
+					seen[entry.partOfSpeech] = true;
+					var option = element("option");
+					option.value = entry.partOfSpeech;
+					posList.appendChild(option);
+				}
+			});
+		}
+
+		function renderSenses(entry) {
+			var container = $("senses");
+			container.textContent = "";
+			entry.senses.forEach(function (sense, i) {
+				var box = element("div", "sense");
+				var heading = element("h3", "", "Sense " + (i + 1));
+				var remove = element("button", "danger", "Remove sense");
+				remove.type = "button";
+				remove.addEventListener("click", function () {
+					readForm();
+					entry.senses.splice(i, 1);
+					renderSenses(entry);
+				});
+				heading.appendChild(remove);
+				box.appendChild(heading);
+
+				var glosses = element("label", "", "Glosses ");
+				glosses.appendChild(element("small", "", "(short translations, separated by semicolons)"));
+				var glossInput = element("input", "glosses");
+				glossInput.type = "text";
+				glossInput.value = (sense.glosses || []).join("; ");
+				glosses.appendChild(glossInput);
+				box.appendChild(glosses);
+
+				var definitions = element("label", "", "Definitions ");
+				definitions.appendChild(element("small", "", "(one per line)"));
+				var defInput = element("textarea", "definitions");
+				defInput.value = (sense.definitions || []).map(function (def) {
+					return def.text;
+				}).join("\n");
+				definitions.appendChild(defInput);
+				box.appendChild(definitions);
+
+				container.appendChild(box);
+			});
+		}
+
+		function renderForm() {
+			var entry = state.current;
+			if (!entry.senses || entry.senses.length === 0) {
+				entry.senses = [{}];
+			}
+			$("editor").hidden = false;
+			$("heading").textContent = state.isNew ? "New entry" : entry.word;
+			$("word").value = entry.word || "";
+			$("homograph").value = entry.homograph || "";
+			$("pos").value = entry.partOfSpeech || "";
+			$("pronunciations").value = (entry.pronunciations || []).map(function (p) {
+				return p.text;
+			}).join("; ");
+			$("variants").value = (entry.variants || []).join("; ");
+			$("etymology").value = entry.etymology || "";
+			$("usage-notes").value = (entry.usageNotes || []).join("\n");
+			$("json").value = JSON.stringify(entry, null, 2);
+			$("delete").hidden = state.isNew;
+			renderSenses(entry);
+			renderList();
+		}
+
+		function setOrDelete(object, key, value) {
+			if (value === "" || value === 0 || (Array.isArray(value) && value.length === 0)) {
+				delete object[key];
+			} else {
+				object[key] = value;
+			}
+		}
+
+		// Copy the form into the current entry, keeping the fields the form does not
+		// show, such as the qualifiers of definitions.
+		function readForm() {
+			var entry = state.current;
+			entry.word = $("word").value;
+			setOrDelete(entry, "homograph", parseInt($("homograph").value, 10) || 0);
+			entry.partOfSpeech = $("pos").value;
+
+			var old = entry.pronunciations || [];
+			setOrDelete(entry, "pronunciations", splitList($("pronunciations").value, ";").map(function (value, i) {
+				var p = old[i] ? Object.assign({}, old[i]) : {};
+				p.text = value;
+				return p;
+			}));
+			setOrDelete(entry, "variants", splitList($("variants").value, ";"));
+			setOrDelete(entry, "etymology", $("etymology").value.trim());
+			setOrDelete(entry, "usageNotes", splitList($("usage-notes").value, "\n"));
+
+			var boxes = $("senses").querySelectorAll(".sense");
+			boxes.forEach(function (box, i) {
+				var sense = entry.senses[i];
+				setOrDelete(sense, "glosses", splitList(box.querySelector(".glosses").value, ";"));
+				var oldDefs = sense.definitions || [];
+				setOrDelete(sense, "definitions", splitList(box.querySelector(".definitions").value, "\n").map(function (text, j) {
+					var def = oldDefs[j] ? Object.assign({}, oldDefs[j]) : {};
+					def.text = text;
+					return def;
+				}));
+			});
+			$("json").value = JSON.stringify(entry, null, 2);
+			return entry;
+		}
+
+		function select(id) {
+			var entry = state.entries.find(function (entry) {
+				return entry.id === id;
+			});
+			if (!entry) {
+				return;
+			}
+			state.current = JSON.parse(JSON.stringify(entry));
+			state.isNew = false;
+			showMessage("");
+			var index = state.entries.indexOf(entry);
+			showIssues(issuesByEntry()[index]);
+			renderForm();
+		}
+
+		function load() {
+			setStatus("Loading…");
+			return request("GET", "/api/lexicon").then(function (response) {
+				if (response.status !== 200) {
+					setStatus(response.data.error);
+					return;
+				}
+				state.entries = response.data.entries;
+				state.issues = response.data.issues;
+				state.etag = response.etag;
+				$("title").textContent = response.data.languageName + " lexicon";
+				document.title = response.data.languageName + " lexicon – llex editor";
+				setStatus(state.entries.length + " entries, " + state.issues.length + " issues");
+				renderList();
+			});
+		}
+
+		function save() {
+			var entry = readForm();
+			entry.senses = entry.senses.filter(function (sense) {
+				return Object.keys(sense).length > 0;
+			});
+			var method = state.isNew ? "POST" : "PUT";
+			var url = state.isNew ? "/api/entries" : "/api/entries/" + encodeURIComponent(entry.id);
+			setStatus("Saving…");
+
+			request(method, url, entry).then(function (response) {
+				switch (response.status) {
+				case 200:
+					state.isNew = false;
+					state.current = response.data.entry;
+					return load().then(function () {
+						select(response.data.entry.id);
+						showMessage(response.data.issues.length ? "Saved, with warnings:" : "Saved.", false);
+						showIssues(response.data.issues);
+					});
+				case 412:
+					setStatus("Not saved");
+					showMessage("The lexicon file was changed outside the editor. Your changes have not been saved; copy them from the JSON box if you need them, then reload the page.", true);
+					return;
+				case 422:
+					setStatus("Not saved");
+					showMessage("Not saved: the entry has problems that must be fixed first.", true);
+					showIssues(response.data.issues);
+					return;
+				default:
+					setStatus("Not saved");
+					showMessage(response.data.error, true);
+				}
+			}).catch(function (err) {
+				setStatus("Not saved");
+				showMessage("Could not reach the editor: " + err.message, true);
+			});
+		}
+
+		function remove() {
+			var entry = state.current;
+			if (!confirm("Delete " + entry.word + "? This cannot be undone from the editor.")) {
+				return;
+			}
+			request("DELETE", "/api/entries/" + encodeURIComponent(entry.id)).then(function (response) {
+				if (response.status === 412) {
+					showMessage("The lexicon file was changed outside the editor. Reload the page and try again.", true);
+					return;
+				}
+				if (response.status === 422) {
+					showMessage("Not deleted: deleting the entry would cause problems that must be fixed first.", true);
+					showIssues(response.data.issues);
+					return;
+				}
+				if (response.status !== 200) {
+					showMessage(response.data.error, true);
+					return;
+				}
+				state.current = null;
+				$("editor").hidden = true;
+				var issues = response.data.issues;
+				load().then(function () {
+					if (issues.length) {
+						alert("Deleted " + entry.word + ", with warnings:\n\n" + issues.map(function (issue) {
+							return issue.word + ": " + issue.message;
+						}).join("\n"));
+					}
+				});
+			});
+		}
+
+		$("editor").addEventListener("submit", function (event) {
+			event.preventDefault();
+			save();
+		});
+		$("new").addEventListener("click", function () {
+			state.current = { word: "", partOfSpeech: "", senses: [{}] };
+			state.isNew = true;
+			showMessage("");
+			showIssues([]);
+			renderForm();
+			$("word").focus();
+		});
+		$("add-sense").addEventListener("click", function () {
+			readForm();
+			state.current.senses.push({});
+			renderSenses(state.current);
+		});
+		$("apply-json").addEventListener("click", function () {
+			try {
+				var entry = JSON.parse($("json").value);
+				entry.id = state.current.id;
+				state.current = entry;
+				renderForm();
+				showMessage("");
+			} catch (err) {
+				showMessage("The JSON is not valid: " + err.message, true);
+			}
+		});
+		$("cancel").addEventListener("click", function () {
+			if (state.isNew) {
+				state.current = null;
+				$("editor").hidden = true;
+				renderList();
+			} else {
+				select(state.current.id);
+			}
+		});
+		$("delete").addEventListener("click", remove);
+		$("search").addEventListener("input", renderList);
+
+		load();
+	})();
+	</script>
+</body>
+</html>
+`
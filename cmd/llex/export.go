@@ -2,20 +2,16 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"html"
+	"html/template"
 	"os"
-	"slices"
 
 	"github.com/a-random-lemurian/lemurian-lexicon/llex"
 	"github.com/urfave/cli/v2"
 )
 
-var supportedExportFormats = []string{
-	"html",    // Single-file HTML
-	"website", // Static website or directory
-}
-
 // Get the contents of a file that can be passed in through command-line arguments.
 // Used for optional files.
 //
@@ -34,25 +30,50 @@ func escapeHtml(text string, escape bool) string {
 	return text
 }
 
-func getAuxillaryHTMLFiles(cCtx *cli.Context, params *llex.ExportParams) error {
+// The export settings for a target, with those given as flags taking precedence
+// over those from llex.toml.
+func exportSettings(cCtx *cli.Context, config *llex.Config, target *llex.ExportTarget) llex.ExportSettings {
+	var settings llex.ExportSettings
+	if config != nil {
+		settings = config.TargetSettings(target)
+	}
+
+	if cCtx.IsSet("author") {
+		settings.Author = cCtx.String("author")
+	}
+	if cCtx.IsSet("copyright") {
+		settings.Copyright = cCtx.String("copyright")
+	}
+	if cCtx.IsSet("authors-note") {
+		settings.AuthorsNote = cCtx.String("authors-note")
+	}
+	if cCtx.IsSet("treat-as-html") {
+		treatAsHtml := cCtx.Bool("treat-as-html")
+		settings.TreatAsHTML = &treatAsHtml
+	}
+
+	return settings
+}
+
+func getAuxillaryHTMLFiles(settings llex.ExportSettings, params *llex.ExportParams) error {
 	// Retrieve authors' note and copyright text.
-	treatAsHtml := cCtx.Bool("treat-as-html")
+	treatAsHtml := settings.TreatAsHTML != nil && *settings.TreatAsHTML
 
 	// Only overwrite params.Copyright so that if the copyright file is an empty string,
 	// the default single-file HTML export will still explicitly state that no copyright
 	// information was provided.
-	copyrightBytes, err := getFileOptional(cCtx.String("copyright"))
+	copyrightBytes, err := getFileOptional(settings.Copyright)
 	if err != nil {
 		return err
 	}
 	copyright := string(copyrightBytes)
-	copyright = escapeHtml(copyright, treatAsHtml)
+	copyright = escapeHtml(copyright, !treatAsHtml)
 	if copyright != "" {
 		params.Copyright = copyright
 	}
 
-	authorsNoteBytes, err := getFileOptional(cCtx.String("authors-note"))
-	authorsNote := escapeHtml(string(authorsNoteBytes), treatAsHtml)
+	authorsNoteBytes, err := getFileOptional(settings.AuthorsNote)
+	authorsNote := escapeHtml(string(authorsNoteBytes), !treatAsHtml)
 	if err != nil {
 		return err
 	}
@@ -61,67 +82,188 @@ func getAuxillaryHTMLFiles(cCtx *cli.Context, params *llex.ExportParams) error {
 	return nil
 }
 
-func cmdExport(cCtx *cli.Context) error {
-	exportFmt := cCtx.String("format")
+// Set up the theme, stylesheet and templates for HTML exports, with those given as
+// flags taking precedence over those from llex.toml.
+func exportTemplates(cCtx *cli.Context, config *llex.Config, params *llex.ExportParams) error {
+	var templates llex.TemplateConfig
+	if config != nil {
+		templates = config.Templates
+		if config.Language.GlossLanguage != "" {
+			params.GlossLanguage = config.Language.GlossLanguage
+		}
+	}
+	if cCtx.IsSet("theme") {
+		// The configuration's stylesheet replaces its theme, so a theme given on
+		// the command line replaces the stylesheet too.
+		templates.Theme = cCtx.String("theme")
+		templates.CSS = ""
+	}
+	if cCtx.IsSet("template-dir") {
+		templates.Dir = cCtx.String("template-dir")
+	}
+
+	css, err := llex.ThemeCSS(templates.Theme)
+	if err != nil {
+		return err
+	}
+	params.CSS = template.CSS(css)
+
+	if templates.CSS != "" {
+		css, err := os.ReadFile(templates.CSS)
+		if err != nil {
+			return err
+		}
+		params.CSS = template.CSS(css)
+	}
+
+	if templates.Dir != "" {
+		params.Templates, err = llex.LoadTemplates(templates.Dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read the lexicon to export: the one given with --input, or the one named in
+// llex.toml, with the configuration's language settings applied.
+func readExportInput(cCtx *cli.Context, config *llex.Config) (*llex.Dictionary, error) {
 	inputFile := cCtx.String("input")
-	outputPath := cCtx.String("output")
+	if inputFile == "" && config != nil {
+		inputFile = config.Lexicon
+	}
+	if inputFile == "" {
+		return nil, errors.New("no lexicon given; use --input or set lexicon in " + llex.ConfigFileName)
+	}
 
-	if !slices.Contains(supportedExportFormats, exportFmt) {
-		return &ErrorUnsupportedFormat{attemptedFormat: exportFmt}
+	input, err := openInput(inputFile)
+	if err != nil {
+		return nil, err
 	}
+	defer input.Close()
 
-	dictionaryRawJson, err := os.ReadFile(inputFile)
+	dictionary, _, err := llex.ReadDictionaryWithParams(input, &llex.ReadParams{DisallowUnknownFields: cCtx.Bool("strict")})
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%s: %w", inputDisplayName(inputFile), err)
+	}
+
+	if config != nil {
+		if err := config.Apply(dictionary); err != nil {
+			return nil, err
+		}
 	}
 
-	var dictionary llex.Dictionary
-	err = json.Unmarshal(dictionaryRawJson, &dictionary)
+	return dictionary, nil
+}
+
+// Set up the parameters for exporting a dictionary to a target.
+func targetExportParams(cCtx *cli.Context, config *llex.Config, dictionary *llex.Dictionary, target *llex.ExportTarget, exporter llex.Exporter) (*llex.ExportParams, error) {
+	options, err := mergeFormatOptions(target.Options, cCtx.StringSlice("option"), exporter.Info())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var output string
+	params := llex.NewExportParams(dictionary)
 
-	params := llex.NewExportParams(&dictionary)
+	settings := exportSettings(cCtx, config, target)
+	params.Author = settings.Author
+	params.Columns = target.Columns
+	if cCtx.IsSet("columns") {
+		params.Columns = columnList(cCtx.String("columns"))
+	}
+	params.Options = options
 
-	params.Author = cCtx.String("author")
+	if err := exportTemplates(cCtx, config, params); err != nil {
+		return nil, err
+	}
 
-	err = getAuxillaryHTMLFiles(cCtx, params)
+	err = getAuxillaryHTMLFiles(settings, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// Export a dictionary to a single target.
+func exportTarget(cCtx *cli.Context, config *llex.Config, dictionary *llex.Dictionary, target *llex.ExportTarget) error {
+	exporter, ok := llex.LookupExporter(target.Format)
+	if !ok {
+		return &ErrorUnsupportedFormat{attemptedFormat: target.Format}
+	}
+
+	params, err := targetExportParams(cCtx, config, dictionary, target, exporter)
 	if err != nil {
 		return err
 	}
 
-	// llex.ExportStaticHTML handles the file-writing logic, so return early
-	// if the website option is selected.
-	if exportFmt == "website" {
-		params.OutputPath = outputPath
-		return llex.ExportStaticHTML(params)
+	outputPath := target.Output
+
+	// Formats such as websites handle the file-writing logic themselves, so
+	// return early if one of them is selected.
+	if dirExporter, ok := exporter.(llex.DirectoryExporter); ok {
+		if outputPath == "" || outputPath == stdioName {
+			return fmt.Errorf("format '%s' must be exported to a directory; use --output", target.Format)
+		}
+		return dirExporter.ExportDirectory(outputPath, params)
 	}
 
 	// Attempt to create the output file before starting the generation
 	// process, so that if there is a problem with the output file, time
 	// is not wasted generating a result that will never be written.
-	outputFile, err := os.Create(outputPath)
+	outputFile, err := createOutput(outputPath)
 	if err != nil {
 		return err
 	}
 	defer outputFile.Close()
 	writer := bufio.NewWriter(outputFile)
 
-	switch exportFmt {
-	case "html":
-		output, err = llex.ExportSinglePageHTML(params)
+	err = exporter.Export(writer, params)
+	if err != nil {
+		return err
 	}
 
+	return writer.Flush()
+}
+
+// Export a lexicon to the format given with --format, or, without one, to every
+// target in llex.toml.
+func cmdExport(cCtx *cli.Context) error {
+	config, err := loadConfig(cCtx)
 	if err != nil {
 		return err
 	}
 
-	_, err = writer.WriteString(output)
+	var targets []*llex.ExportTarget
+	if exportFmt := cCtx.String("format"); exportFmt != "" {
+		targets = []*llex.ExportTarget{{Format: exportFmt, Output: cCtx.String("output")}}
+	} else if config != nil && len(config.Targets) > 0 {
+		if cCtx.IsSet("output") {
+			return errors.New("--output can only be used with --format")
+		}
+		targets = config.Targets
+	} else {
+		return errors.New("no format given; use --format or add targets to " + llex.ConfigFileName)
+	}
+
+	dictionary, err := readExportInput(cCtx, config)
 	if err != nil {
 		return err
 	}
 
-	return writer.Flush()
+	for _, target := range targets {
+		if err := exportTarget(cCtx, config, dictionary, target); err != nil {
+			return err
+		}
+		if len(targets) > 1 {
+			output := target.Output
+			if output == "" {
+				output = stdioName
+			}
+			fmt.Fprintf(os.Stderr, "Exported %s to %s\n", target.Format, output)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// The file name that stands for standard input or output.
+const stdioName = "-"
+
+// Open a file to read from, or standard input if the name is "-".
+func openInput(name string) (io.ReadCloser, error) {
+	if name == stdioName {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// A name for an input file to show in messages.
+func inputDisplayName(name string) string {
+	if name == stdioName {
+		return "<stdin>"
+	}
+	return name
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// Create a file to write to, or use standard output if the name is "-" or empty.
+func createOutput(name string) (io.WriteCloser, error) {
+	if name == stdioName || name == "" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(name)
+}
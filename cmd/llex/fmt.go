@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+// Read a lexicon for reformatting. Unknown fields are rejected, since writing the
+// dictionary back out would silently remove them.
+func readForFormatting(data []byte) (*llex.Dictionary, error) {
+	dict, _, err := llex.ReadDictionaryWithParams(bytes.NewReader(data), &llex.ReadParams{DisallowUnknownFields: true})
+	return dict, err
+}
+
+func cmdFmt(cCtx *cli.Context) error {
+	files := cCtx.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("no lexicon files given")
+	}
+
+	check := cCtx.Bool("check")
+	unformatted := 0
+
+	for _, file := range files {
+		original, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		dict, err := readForFormatting(original)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		formatted, err := llex.FormatDictionary(dict)
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(original, formatted) {
+			continue
+		}
+
+		if check {
+			fmt.Println(file)
+			unformatted++
+			continue
+		}
+
+		if err := llex.WriteDictionary(file, dict); err != nil {
+			return err
+		}
+	}
+
+	if unformatted > 0 {
+		return cli.Exit(fmt.Sprintf("%d file(s) not in canonical form; run llex fmt to fix them", unformatted), 1)
+	}
+
+	return nil
+}
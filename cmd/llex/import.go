@@ -3,18 +3,14 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"maps"
 	"os"
-
-	"encoding/json"
+	"slices"
 
 	"github.com/a-random-lemurian/lemurian-lexicon/llex"
 	"github.com/urfave/cli/v2"
 )
 
-var supportedImportFormats = []string{
-	"lp", // Lexique Pro database files
-}
-
 type ErrorUnsupportedFormat struct {
 	attemptedFormat string
 }
@@ -23,44 +19,107 @@ func (e *ErrorUnsupportedFormat) Error() string {
 	return "unsupported format '" + e.attemptedFormat + "'"
 }
 
+// Warn about anything that was skipped during an import.
+func printImportReport(report *llex.ImportReport) {
+	for _, skipped := range report.Skipped {
+		fmt.Fprintln(os.Stderr, "warning: skipped "+skipped.Error())
+	}
+
+	if len(report.Skipped) == 0 {
+		return
+	}
+
+	counts := report.SkippedMarkers()
+	markers := slices.Sorted(maps.Keys(counts))
+	summary := make([]string, len(markers))
+	for i, marker := range markers {
+		summary[i] = fmt.Sprintf("%s (%d)", marker, counts[marker])
+	}
+	fmt.Fprintf(os.Stderr, "warning: skipped %d fields: %s\n", len(report.Skipped), strSliceCommaList(summary))
+}
+
 func cmdImport(cCtx *cli.Context) error {
 	importFmt := cCtx.String("format")
 	importFile := cCtx.String("input")
 	outputFile := cCtx.String("output")
 
-	// Todo: Do not hardcode once extra formats added
-	if importFmt != "lp" {
-		return &ErrorUnsupportedFormat{attemptedFormat: importFmt}
+	config, err := loadConfig(cCtx)
+	if err != nil {
+		return err
 	}
 
-	dict, err := llex.ImportFromLexiquePro(importFile)
+	input, err := openInput(importFile)
 	if err != nil {
 		return err
 	}
+	defer input.Close()
+	reader := bufio.NewReaderSize(input, llex.SniffLength)
+	importName := inputDisplayName(importFile)
 
-	dict.LanguageName = cCtx.String("language-name")
+	var importer llex.Importer
+	if importFmt == "" {
+		// Peek returns an error when the file is shorter than SniffLength, which
+		// does not matter here.
+		head, _ := reader.Peek(llex.SniffLength)
+		importer, err = llex.DetectImportFormat(importName, head)
+		if err != nil {
+			return fmt.Errorf("%w; use --format to choose one", err)
+		}
+		fmt.Fprintln(os.Stderr, "Detected format: "+importer.Info().Name)
+	} else {
+		var ok bool
+		importer, ok = llex.LookupImporter(importFmt)
+		if !ok {
+			return &ErrorUnsupportedFormat{attemptedFormat: importFmt}
+		}
+	}
 
-	dictJson, err := json.Marshal(dict)
+	options, err := formatOptions(cCtx.StringSlice("option"), importer.Info())
 	if err != nil {
 		return err
 	}
 
-	if outputFile == "" {
-		fmt.Println(string(dictJson))
-		return nil
+	params := &llex.ImportParams{
+		Filename: importName,
+		Strict:   cCtx.Bool("strict"),
+		Columns:  columnList(cCtx.String("columns")),
+		Options:  options,
+
+		CaseSensitive: cCtx.Bool("case-sensitive"),
+		Normalization: cCtx.String("normalization"),
 	}
 
-	file, err := os.Create(outputFile)
-	if err != nil {
-		return err
+	languageName := cCtx.String("language-name")
+
+	// Fall back to the project's language settings.
+	if config != nil {
+		if !cCtx.IsSet("case-sensitive") && config.Language.CaseSensitive != nil {
+			params.CaseSensitive = *config.Language.CaseSensitive
+		}
+		if params.Normalization == "" {
+			params.Normalization = config.Language.Normalization
+		}
+		if languageName == "" {
+			languageName = config.Language.Name
+		}
 	}
-	defer file.Close()
 
-	writer := bufio.NewWriter(file)
-	_, err = writer.Write(dictJson)
+	dict, report, err := importer.Import(reader, params)
 	if err != nil {
 		return err
 	}
 
-	return writer.Flush()
+	printImportReport(report)
+
+	// Formats such as llex's own record the language name, which is kept unless
+	// another is given.
+	if languageName != "" {
+		dict.LanguageName = languageName
+	}
+
+	if outputFile == "" || outputFile == stdioName {
+		return llex.WriteDictionaryTo(os.Stdout, dict)
+	}
+
+	return llex.WriteDictionary(outputFile, dict)
 }
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+// The result of linting one file, as printed by lint --json.
+type lintResult struct {
+	File   string            `json:"file"`
+	Fixed  int               `json:"fixed,omitempty"`
+	Issues []*llex.LintIssue `json:"issues"`
+}
+
+func describeLintRules() string {
+	output := "Lint rules:\n"
+	for _, rule := range llex.LintRules() {
+		fixable := ""
+		if rule.Fixable() {
+			fixable = ", fixable"
+		}
+		output += fmt.Sprintf("  %-20s %s (%s%s)\n", rule.Name, rule.Description, rule.Severity, fixable)
+	}
+	return output
+}
+
+// Lint a single file, fixing it first if asked to.
+func lintFile(file string, params *llex.LintParams, fix bool) (*lintResult, error) {
+	dict, err := llex.ReadDictionary(file)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &lintResult{File: file}
+
+	if fix {
+		result.Fixed, err = llex.FixLint(dict, params)
+		if err != nil {
+			return nil, err
+		}
+		if result.Fixed > 0 {
+			if err := llex.WriteDictionary(file, dict); err != nil {
+				return nil, err
+			}
+			// Read the file again, so that the paths of the remaining issues refer
+			// to the entries as they were written.
+			if dict, err = llex.ReadDictionary(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	report, err := llex.Lint(dict, params)
+	if err != nil {
+		return nil, err
+	}
+	result.Issues = report.Issues
+
+	return result, nil
+}
+
+func cmdLint(cCtx *cli.Context) error {
+	if cCtx.Bool("list-rules") {
+		fmt.Print(describeLintRules())
+		return nil
+	}
+
+	files := cCtx.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("no lexicon files given")
+	}
+
+	params := &llex.LintParams{
+		Enable:  columnList(cCtx.String("enable")),
+		Disable: columnList(cCtx.String("disable")),
+	}
+	if _, err := params.Rules(); err != nil {
+		return err
+	}
+
+	var results []*lintResult
+	errorCount := 0
+	warningCount := 0
+
+	for _, file := range files {
+		result, err := lintFile(file, params, cCtx.Bool("fix"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		results = append(results, result)
+
+		for _, issue := range result.Issues {
+			if issue.Severity == llex.LintError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+	}
+
+	if cCtx.Bool("json") {
+		output, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+	} else {
+		for _, result := range results {
+			if result.Fixed > 0 {
+				fmt.Printf("%s: fixed %d issue(s)\n", result.File, result.Fixed)
+			}
+			for _, issue := range result.Issues {
+				location := issue.Path
+				if issue.Word != "" {
+					location += " (" + strings.TrimSpace(issue.Word) + ")"
+				}
+				fmt.Printf("%s: %s: %s: %s [%s]\n", result.File, location, issue.Severity, issue.Message, issue.Rule)
+			}
+		}
+		if errorCount+warningCount > 0 {
+			fmt.Printf("%d error(s), %d warning(s)\n", errorCount, warningCount)
+		}
+	}
+
+	if errorCount > 0 {
+		return cli.Exit("", 1)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
 	"github.com/urfave/cli/v2"
 )
 
@@ -18,22 +19,72 @@ func strSliceCommaList(slice []string) string {
 	return output
 }
 
+// Information about each format in a list of importers or exporters.
+func formatInfos[F interface{ Info() *llex.FormatInfo }](formats []F) []*llex.FormatInfo {
+	infos := make([]*llex.FormatInfo, len(formats))
+	for i, format := range formats {
+		infos[i] = format.Info()
+	}
+	return infos
+}
+
+func formatNames(infos []*llex.FormatInfo) []string {
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
+	}
+	return names
+}
+
+// Describe each format on its own lines, along with its extensions and options.
+func describeFormats(infos []*llex.FormatInfo) string {
+	output := ""
+	for _, info := range infos {
+		output += fmt.Sprintf("  %-10s %s\n", info.Name, info.Description)
+		if len(info.Extensions) > 0 {
+			output += fmt.Sprintf("  %-10s Extensions: %s\n", "", strSliceCommaList(info.Extensions))
+		}
+		for _, option := range info.Options {
+			output += fmt.Sprintf("  %-10s Option %s: %s\n", "", option.Name, option.Usage)
+		}
+	}
+	return output
+}
+
 func cmdListFormats(cCtx *cli.Context) error {
 	var supportedFormatString string
 
+	importFormats := formatInfos(llex.Importers())
+	exportFormats := formatInfos(llex.Exporters())
+	verbose := cCtx.Bool("verbose")
+
 	if cCtx.Bool("json") {
-		type formatObject struct {
-			Export []string `json:"export"`
-			Import []string `json:"import"`
+		var supportedFormatsJSON []byte
+		var err error
+
+		if verbose {
+			type formatObject struct {
+				Export []*llex.FormatInfo `json:"export"`
+				Import []*llex.FormatInfo `json:"import"`
+			}
+			supportedFormatsJSON, err = json.Marshal(formatObject{Export: exportFormats, Import: importFormats})
+		} else {
+			type formatObject struct {
+				Export []string `json:"export"`
+				Import []string `json:"import"`
+			}
+			supportedFormatsJSON, err = json.Marshal(formatObject{Export: formatNames(exportFormats), Import: formatNames(importFormats)})
 		}
-		supportedFormatsJSON, err := json.Marshal(formatObject{Export: supportedExportFormats, Import: supportedImportFormats})
 		if err != nil {
 			return err
 		}
 		supportedFormatString = string(supportedFormatsJSON)
+	} else if verbose {
+		supportedFormatString += "Supported import formats:\n" + describeFormats(importFormats) + "\n"
+		supportedFormatString += "Supported export formats:\n" + describeFormats(exportFormats)
 	} else {
-		supportedFormatString += "Supported import formats: " + strSliceCommaList(supportedImportFormats) + "\n"
-		supportedFormatString += "Supported export formats: " + strSliceCommaList(supportedExportFormats)
+		supportedFormatString += "Supported import formats: " + strSliceCommaList(formatNames(importFormats)) + "\n"
+		supportedFormatString += "Supported export formats: " + strSliceCommaList(formatNames(exportFormats))
 	}
 
 	fmt.Println(supportedFormatString)
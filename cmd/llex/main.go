@@ -1,8 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
 	"github.com/urfave/cli/v2"
 )
 
@@ -10,6 +14,11 @@ func main() {
 	app := &cli.App{
 		Usage:   "A program for managing conlang lexicons",
 		Authors: []*cli.Author{{Name: "Lemuria"}},
+		// Format options such as --option columns=word,pos contain commas.
+		DisableSliceFlagSeparator: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "config", Usage: "Project configuration file. Found by looking for llex.toml in the working directory and the directories above it if not given."},
+		},
 		Commands: []*cli.Command{
 			{
 				Name:    "import",
@@ -17,10 +26,15 @@ func main() {
 				Usage:   "Import a lexicon from another format.",
 				Action:  cmdImport,
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "format", Usage: "Format of the file to import from", Required: true, Aliases: []string{"f"}},
-					&cli.StringFlag{Name: "input", Usage: "File to import from", Required: true, Aliases: []string{"i"}},
-					&cli.StringFlag{Name: "output", Usage: "File to output LLEX json to. lp for Lexique Pro .db files, the only supported file format.", Aliases: []string{"o"}},
-					&cli.StringFlag{Name: "language-name", Usage: "Name of the language to be imported (Lexique Pro does not include the name)"},
+					&cli.StringFlag{Name: "format", Usage: "Format of the file to import from. See list-formats. Detected from the file if not given.", Aliases: []string{"f"}},
+					&cli.StringFlag{Name: "input", Usage: "File to import from, or - for standard input", Required: true, Aliases: []string{"i"}},
+					&cli.StringFlag{Name: "output", Usage: "File to output LLEX json to. Standard output if not given or -.", Aliases: []string{"o"}},
+					&cli.StringFlag{Name: "language-name", Usage: "Name of the language to be imported (Lexique Pro does not include the name). Taken from llex.toml if not given."},
+					&cli.BoolFlag{Name: "strict", Usage: "Fail on anything that cannot be imported, instead of skipping it with a warning"},
+					&cli.StringFlag{Name: "columns", Usage: "For csv and tsv, a comma-separated list of the field in each column (e.g. word,pos,definitions), instead of reading them from the header. Leave a name blank to ignore that column."},
+					&cli.StringSliceFlag{Name: "option", Usage: "A format-specific option, as name=value. See list-formats --verbose.", Aliases: []string{"O"}},
+					&cli.BoolFlag{Name: "case-sensitive", Usage: "Treat headwords that only differ in case as different words. Taken from llex.toml if not given."},
+					&cli.StringFlag{Name: "normalization", Usage: "Unicode normalization form to keep the lexicon's text in, NFC or NFD. Taken from llex.toml if not given."},
 				},
 			},
 			{
@@ -29,30 +43,122 @@ func main() {
 				Usage:   "Export a lexicon.",
 				Action:  cmdExport,
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "format", Usage: "Format to export into", Required: true, Aliases: []string{"f"}},
-					&cli.StringFlag{Name: "input", Usage: "File to import from", Required: true, Aliases: []string{"i"}},
+					&cli.StringFlag{Name: "format", Usage: "Format to export into. See list-formats. Without it, every target in llex.toml is exported.", Aliases: []string{"f"}},
+					&cli.StringFlag{Name: "input", Usage: "LLEX json file to export, or - for standard input. The lexicon in llex.toml if not given.", Aliases: []string{"i"}},
 
-					// We call it the output path, because the format can either be a single file or a directory (in the case of a website export.)
-					&cli.StringFlag{Name: "output", Usage: "Path to output the exported lexicon to.", Aliases: []string{"o"}},
+					// We call it the output path, because the format can either be a single
+					// file or a directory (in the case of a website export.)
+					&cli.StringFlag{Name: "output", Usage: "Path to output the exported lexicon to. Standard output if not given or -, except for formats that write a directory.", Aliases: []string{"o"}},
 
 					&cli.StringFlag{Name: "author", Usage: "The name of the conlang's author, or authors"},
 					&cli.StringFlag{Name: "copyright", Usage: "Path to a file with copyright information."},
 					&cli.StringFlag{Name: "authors-note", Usage: "Path to a file with an authors' note."},
 					&cli.BoolFlag{Name: "treat-as-html", Usage: "If the export format is HTML, treat the copyright and authors' note files as HTML, not plaintext."},
+					&cli.StringFlag{Name: "columns", Usage: "For csv and tsv, a comma-separated list of the fields to export, in order"},
+					&cli.StringFlag{Name: "template-dir", Usage: "For HTML formats, a directory of templates (page.html, entry.html, navbar.html, style.css) to use instead of the built-in ones"},
+					&cli.StringFlag{Name: "theme", Usage: "For HTML formats, the built-in theme to use: " + strings.Join(llex.ThemeNames(), ", ")},
+					&cli.StringSliceFlag{Name: "option", Usage: "A format-specific option, as name=value. See list-formats --verbose.", Aliases: []string{"O"}},
+					&cli.BoolFlag{Name: "strict", Usage: "Fail if the lexicon has fields that llex does not know about, instead of ignoring them"},
+				},
+			},
+			{
+				Name:   "serve",
+				Usage:  "Serve a lexicon's website locally, rebuilding it and reloading the browser when the lexicon, llex.toml or templates change. With --api, serve a JSON API instead.",
+				Action: cmdServe,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "input", Usage: "LLEX json file to serve. The lexicon in llex.toml if not given.", Aliases: []string{"i"}},
+					&cli.StringFlag{Name: "addr", Usage: "Address to listen on", Value: "localhost:8080"},
+					&cli.BoolFlag{Name: "api", Usage: "Serve a read-only JSON API for looking up words under /api/, instead of the website"},
+					&cli.BoolFlag{Name: "watch", Usage: "Rebuild the website when the files it is built from change", Value: true},
+					&cli.DurationFlag{Name: "interval", Usage: "How often to check the files for changes", Value: 500 * time.Millisecond},
+					&cli.StringFlag{Name: "author", Usage: "The name of the conlang's author, or authors"},
+					&cli.StringFlag{Name: "copyright", Usage: "Path to a file with copyright information."},
+					&cli.StringFlag{Name: "authors-note", Usage: "Path to a file with an authors' note."},
+					&cli.BoolFlag{Name: "treat-as-html", Usage: "Treat the copyright and authors' note files as HTML, not plaintext."},
+					&cli.StringFlag{Name: "template-dir", Usage: "A directory of templates (page.html, entry.html, navbar.html, style.css) to use instead of the built-in ones"},
+					&cli.StringFlag{Name: "theme", Usage: "The built-in theme to use: " + strings.Join(llex.ThemeNames(), ", ")},
+					&cli.StringSliceFlag{Name: "option", Usage: "A website option, as name=value. See list-formats --verbose.", Aliases: []string{"O"}},
+					&cli.BoolFlag{Name: "strict", Usage: "Fail if the lexicon has fields that llex does not know about, instead of ignoring them"},
+				},
+			},
+			{
+				Name:      "edit",
+				Usage:     "Edit a lexicon in the browser. Changes are checked with the lint rules and saved in canonical form.",
+				ArgsUsage: "[FILE]",
+				Action:    cmdEdit,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "addr", Usage: "Address to listen on", Value: "localhost:8080"},
+				},
+			},
+			{
+				Name:      "fmt",
+				Usage:     "Rewrite lexicon files in the canonical llex form.",
+				ArgsUsage: "FILE...",
+				Action:    cmdFmt,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "check", Usage: "List the files that are not in canonical form instead of rewriting them, and fail if there are any"},
+				},
+			},
+			{
+				Name:      "normalize",
+				Usage:     "Rewrite the text of lexicon files in a Unicode normalization form.",
+				ArgsUsage: "FILE...",
+				Action:    cmdNormalize,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "form", Usage: "Normalization form to use and record in the lexicon, NFC or NFD. The lexicon's own form if not given."},
+					&cli.BoolFlag{Name: "check", Usage: "List the files that are not normalized instead of rewriting them, and fail if there are any"},
 				},
 			},
+			{
+				Name:      "migrate",
+				Usage:     "Upgrade lexicon files written by older versions of llex to the current format.",
+				ArgsUsage: "FILE...",
+				Action:    cmdMigrate,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "dry-run", Usage: "Describe the changes without rewriting the files"},
+				},
+			},
+			{
+				Name:      "lint",
+				Usage:     "Check lexicon files for problems such as missing headwords and duplicate entries.",
+				ArgsUsage: "FILE...",
+				Action:    cmdLint,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "enable", Usage: "Comma-separated list of the only rules to run. See --list-rules."},
+					&cli.StringFlag{Name: "disable", Usage: "Comma-separated list of rules not to run"},
+					&cli.BoolFlag{Name: "json", Usage: "Print the issues found as JSON"},
+					&cli.BoolFlag{Name: "fix", Usage: "Fix the issues that can be fixed mechanically, rewriting the files"},
+					&cli.BoolFlag{Name: "list-rules", Usage: "List the available rules and exit"},
+				},
+			},
+			{
+				Name:   "schema",
+				Usage:  "Print a JSON Schema for llex lexicon files, for editors to use.",
+				Action: cmdSchema,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Usage: "File to write the schema to. Standard output if not given or -.", Aliases: []string{"o"}},
+				},
+			},
+			{
+				Name:      "validate",
+				Usage:     "Check that lexicon files can be read, without unknown or misspelt fields.",
+				ArgsUsage: "FILE...",
+				Action:    cmdValidate,
+			},
 			{
 				Name:   "list-formats",
 				Usage:  "List formats supported by llex",
 				Action: cmdListFormats,
 				Flags: []cli.Flag{
 					&cli.BoolFlag{Name: "json", Usage: "Print the list of supported formats as JSON"},
+					&cli.BoolFlag{Name: "verbose", Usage: "Also describe each format, its file extensions and its options", Aliases: []string{"v"}},
 				},
 			},
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, "llex: "+err.Error())
+		os.Exit(1)
 	}
 }
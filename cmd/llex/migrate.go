@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdMigrate(cCtx *cli.Context) error {
+	files := cCtx.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("no lexicon files given")
+	}
+
+	dryRun := cCtx.Bool("dry-run")
+
+	for _, file := range files {
+		input, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		dict, report, err := llex.MigrateDictionaryFrom(input)
+		input.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		if !report.Migrated() {
+			fmt.Printf("%s: already at format version %d\n", file, report.ToVersion)
+			continue
+		}
+
+		fmt.Printf("%s: format version %d → %d\n", file, report.FromVersion, report.ToVersion)
+		for _, change := range report.Changes {
+			fmt.Println("  " + change)
+		}
+
+		if dryRun {
+			continue
+		}
+
+		if err := llex.WriteDictionary(file, dict); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdNormalize(cCtx *cli.Context) error {
+	files := cCtx.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("no lexicon files given")
+	}
+
+	form := cCtx.String("form")
+	check := cCtx.Bool("check")
+	unnormalized := 0
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		dict, err := readForFormatting(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		declared := dict.Normalization
+		if form != "" {
+			dict.Normalization = form
+		}
+		if dict.Normalization == "" {
+			return fmt.Errorf("%s: the lexicon has no normalization form; give one with --form", file)
+		}
+
+		// Reading the dictionary normalized it to the form it declares, so check
+		// the file itself. Files which are only out of canonical form are left to
+		// llex fmt.
+		normalized, err := llex.IsNormalizedFile(data, dict.Normalization)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if normalized && strings.EqualFold(declared, dict.Normalization) {
+			continue
+		}
+
+		if check {
+			fmt.Println(file)
+			unnormalized++
+			continue
+		}
+
+		if _, err := llex.NormalizeDictionary(dict); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := llex.WriteDictionary(file, dict); err != nil {
+			return err
+		}
+		fmt.Printf("%s: normalized to %s\n", file, dict.Normalization)
+	}
+
+	if unnormalized > 0 {
+		return cli.Exit(fmt.Sprintf("%d file(s) not normalized; run llex normalize to fix them", unnormalized), 1)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+)
+
+// Split the value of a --columns flag into column names. Empty names are kept,
+// since they mark columns to be ignored.
+func columnList(columns string) []string {
+	if columns == "" {
+		return nil
+	}
+	return strings.Split(columns, ",")
+}
+
+// Parse the name=value pairs given with --option, checking that the format
+// understands each of them.
+func formatOptions(pairs []string, info *llex.FormatInfo) (map[string]string, error) {
+	return mergeFormatOptions(nil, pairs, info)
+}
+
+// Like formatOptions, but start from options given elsewhere, such as in llex.toml,
+// which the pairs override.
+func mergeFormatOptions(defaults map[string]string, pairs []string, info *llex.FormatInfo) (map[string]string, error) {
+	options := make(map[string]string)
+	for name, value := range defaults {
+		options[name] = value
+	}
+
+	for _, pair := range pairs {
+		name, value, _ := strings.Cut(pair, "=")
+		options[name] = value
+	}
+
+	for name := range options {
+		known := false
+		for _, option := range info.Options {
+			if option.Name == name {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("format '%s' has no option '%s'", info.Name, name)
+		}
+	}
+
+	return options, nil
+}
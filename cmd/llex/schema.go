@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdSchema(cCtx *cli.Context) error {
+	schema, err := llex.JSONSchema()
+	if err != nil {
+		return err
+	}
+
+	output, err := createOutput(cCtx.String("output"))
+	if err != nil {
+		return err
+	}
+
+	if _, err := output.Write(append(schema, '\n')); err != nil {
+		output.Close()
+		return err
+	}
+	return output.Close()
+}
+
+func cmdValidate(cCtx *cli.Context) error {
+	files := cCtx.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("no lexicon files given")
+	}
+
+	invalid := 0
+
+	for _, file := range files {
+		input, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		_, _, err = llex.ReadDictionaryWithParams(input, &llex.ReadParams{DisallowUnknownFields: true})
+		input.Close()
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return cli.Exit(fmt.Sprintf("%d file(s) are not valid lexicons", invalid), 1)
+	}
+
+	return nil
+}
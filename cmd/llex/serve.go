@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-random-lemurian/lemurian-lexicon/llex"
+	"github.com/urfave/cli/v2"
+)
+
+// The path browsers listen on for reload events.
+const reloadEventsPath = "/_llex/events"
+
+// Added to every page, so that it reloads when the site is rebuilt.
+var reloadScript = []byte(`<script>new EventSource("` + reloadEventsPath + `").addEventListener("reload", function () { location.reload(); });</script>
+`)
+
+// A website kept in memory, rebuilt whenever the files it is built from change.
+// With --api, the lexicon is served through the JSON API instead. See apiHandler.
+type previewSite struct {
+	cCtx *cli.Context
+	api  http.Handler // Nil unless serving the API.
+
+	mu     sync.RWMutex
+	files  map[string][]byte
+	lookup *llex.Lookup
+	err    error // Why the last build failed, if it did.
+
+	clientsMu sync.Mutex
+	clients   map[chan struct{}]bool
+}
+
+// The settings for the website, from the first website target in llex.toml if
+// there is one.
+func websiteTarget(config *llex.Config) *llex.ExportTarget {
+	if config != nil {
+		for _, target := range config.Targets {
+			if target.Format == "website" {
+				return target
+			}
+		}
+	}
+	return &llex.ExportTarget{Format: "website"}
+}
+
+// What a build produces.
+type previewBuild struct {
+	files  map[string][]byte // The website, unless serving the API.
+	lookup *llex.Lookup
+}
+
+// Build the website, returning it and the paths of the files it was built from.
+// The paths are returned even if the build fails, so that fixing the problem
+// triggers another build.
+func (s *previewSite) build() (*previewBuild, []string, error) {
+	var watched []string
+
+	configFile, err := configPath(s.cCtx)
+	if err != nil {
+		return nil, watched, err
+	}
+	if configFile != "" {
+		watched = append(watched, configFile)
+	}
+	if input := s.cCtx.String("input"); input != "" {
+		watched = append(watched, input)
+	}
+
+	config, err := loadConfig(s.cCtx)
+	if err != nil {
+		return nil, watched, err
+	}
+
+	target := websiteTarget(config)
+	settings := exportSettings(s.cCtx, config, target)
+	watched = append(watched, settings.Copyright, settings.AuthorsNote, s.cCtx.String("template-dir"))
+	if config != nil {
+		watched = append(watched, config.Lexicon, config.Templates.Dir, config.Templates.CSS)
+	}
+
+	dictionary, err := readExportInput(s.cCtx, config)
+	if err != nil {
+		return nil, watched, err
+	}
+
+	if s.api != nil {
+		return &previewBuild{lookup: llex.NewLookup(dictionary)}, watched, nil
+	}
+
+	exporter, _ := llex.LookupExporter(target.Format)
+	params, err := targetExportParams(s.cCtx, config, dictionary, target, exporter)
+	if err != nil {
+		return nil, watched, err
+	}
+
+	files := make(map[string][]byte)
+	if err := llex.ExportStaticHTMLTo(llex.MemoryCreateFunc(files), params); err != nil {
+		return nil, watched, err
+	}
+	return &previewBuild{files: files, lookup: llex.NewLookup(dictionary)}, watched, nil
+}
+
+// Rebuild the website and tell browsers to reload it. A failed build replaces the
+// website with an error page until the problem is fixed.
+func (s *previewSite) rebuild() []string {
+	start := time.Now()
+	build, watched, err := s.build()
+
+	s.mu.Lock()
+	if err == nil {
+		s.files = build.files
+		s.lookup = build.lookup
+	}
+	s.err = err
+	s.mu.Unlock()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+	} else {
+		fmt.Fprintf(os.Stderr, "Built the %s in %s\n", s.what(), time.Since(start).Round(time.Millisecond))
+	}
+
+	s.clientsMu.Lock()
+	for client := range s.clients {
+		select {
+		case client <- struct{}{}:
+		default:
+			// The client already has a reload waiting.
+		}
+	}
+	s.clientsMu.Unlock()
+
+	return watched
+}
+
+// Describe the state of a set of files, so that changes can be noticed by
+// comparing descriptions. The files in directories are included.
+func fingerprint(paths []string) string {
+	var b strings.Builder
+	var describe func(name string, depth int)
+	describe = func(name string, depth int) {
+		info, err := os.Stat(name)
+		if err != nil {
+			fmt.Fprintf(&b, "%s: missing\n", name)
+			return
+		}
+		fmt.Fprintf(&b, "%s: %d %d\n", name, info.Size(), info.ModTime().UnixNano())
+
+		if info.IsDir() && depth == 0 {
+			entries, _ := os.ReadDir(name)
+			for _, entry := range entries {
+				describe(filepath.Join(name, entry.Name()), depth+1)
+			}
+		}
+	}
+
+	for _, name := range paths {
+		if name != "" && name != stdioName {
+			describe(name, 0)
+		}
+	}
+	return b.String()
+}
+
+// Rebuild the website whenever one of the files it is built from changes.
+func (s *previewSite) watch(watched []string, interval time.Duration) {
+	last := fingerprint(watched)
+	for range time.Tick(interval) {
+		if current := fingerprint(watched); current != last {
+			watched = s.rebuild()
+			last = fingerprint(watched)
+		}
+	}
+}
+
+// Send an event to the browser each time the website is rebuilt.
+func (s *previewSite) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan struct{}, 1)
+	s.clientsMu.Lock()
+	s.clients[client] = true
+	s.clientsMu.Unlock()
+	defer func() {
+		s.clientsMu.Lock()
+		delete(s.clients, client)
+		s.clientsMu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-client:
+			fmt.Fprint(w, "event: reload\ndata: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// Add the reload script to an HTML page.
+func injectReloadScript(page []byte) []byte {
+	i := bytes.LastIndex(page, []byte("</body>"))
+	if i == -1 {
+		return append(append([]byte(nil), page...), reloadScript...)
+	}
+
+	injected := make([]byte, 0, len(page)+len(reloadScript))
+	injected = append(injected, page[:i]...)
+	injected = append(injected, reloadScript...)
+	return append(injected, page[i:]...)
+}
+
+// The lexicon from the last build, or why the build failed.
+func (s *previewSite) current() (*llex.Lookup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lookup, s.err
+}
+
+// What is being served, for messages.
+func (s *previewSite) what() string {
+	if s.api != nil {
+		return "API"
+	}
+	return "website"
+}
+
+func (s *previewSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.api != nil {
+		s.api.ServeHTTP(w, r)
+		return
+	}
+	if r.URL.Path == reloadEventsPath {
+		s.serveEvents(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	files, buildErr := s.files, s.err
+	s.mu.RUnlock()
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if buildErr != nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		page := "<!DOCTYPE html>\n<html><head><meta charset='utf-8'><title>Build failed</title></head><body><h1>Build failed</h1><pre>" + html.EscapeString(buildErr.Error()) + "</pre></body></html>"
+		w.Write(injectReloadScript([]byte(page)))
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	data, ok := files[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	if path.Ext(name) == ".html" {
+		data = injectReloadScript(data)
+	}
+	w.Write(data)
+}
+
+func cmdServe(cCtx *cli.Context) error {
+	if cCtx.String("input") == stdioName {
+		return fmt.Errorf("llex serve cannot read the lexicon from standard input; give a file")
+	}
+
+	site := &previewSite{cCtx: cCtx, clients: make(map[chan struct{}]bool)}
+	if cCtx.Bool("api") {
+		site.api = apiHandler(site.current)
+	}
+	watched := site.rebuild()
+
+	if cCtx.Bool("watch") {
+		go site.watch(watched, cCtx.Duration("interval"))
+	}
+
+	addr := cCtx.String("addr")
+	fmt.Fprintf(os.Stderr, "Serving the %s at http://%s/\n", site.what(), addr)
+	return http.ListenAndServe(addr, site)
+}
@@ -2,7 +2,11 @@ module github.com/a-random-lemurian/lemurian-lexicon
 
 go 1.23.1
 
-require github.com/urfave/cli/v2 v2.27.6
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/urfave/cli/v2 v2.27.6
+	golang.org/x/text v0.23.0
+)
 
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.5 // indirect
@@ -0,0 +1,183 @@
+package llex
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The name of a project configuration file. See FindConfig.
+const ConfigFileName = "llex.toml"
+
+// Settings for a lexicon project, read from an llex.toml file. Settings given here
+// take precedence over the same settings in the lexicon itself.
+//
+// Paths are relative to the directory the configuration file is in. LoadConfig
+// makes them relative to the working directory instead.
+type Config struct {
+	Lexicon   string          `toml:"lexicon"` // The lexicon file to use when none is given.
+	Language  LanguageConfig  `toml:"language"`
+	Alphabet  *Alphabet       `toml:"alphabet"` // See Dictionary.Alphabet.
+	Templates TemplateConfig  `toml:"templates"`
+	Export    ExportSettings  `toml:"export"`  // Settings shared by every export target.
+	Targets   []*ExportTarget `toml:"targets"` // What llex export builds when no format is given.
+}
+
+// Settings about the language a lexicon is for.
+type LanguageConfig struct {
+	Name          string `toml:"name"`           // See Dictionary.LanguageName.
+	GlossLanguage string `toml:"gloss-language"` // See ExportParams.GlossLanguage.
+	CaseSensitive *bool  `toml:"case-sensitive"` // See Dictionary.CaseSensitive.
+	Normalization string `toml:"normalization"`  // See Dictionary.Normalization.
+}
+
+// Files used to style HTML exports.
+type TemplateConfig struct {
+	Dir   string `toml:"dir"`   // A directory of templates. See LoadTemplates.
+	Theme string `toml:"theme"` // A built-in theme. See ThemeCSS.
+	CSS   string `toml:"css"`   // A stylesheet to use instead of the theme's.
+}
+
+// Settings for exports that can be given for every target or for a single one.
+type ExportSettings struct {
+	Author      string `toml:"author"`
+	Copyright   string `toml:"copyright"`    // Path to a file with copyright information.
+	AuthorsNote string `toml:"authors-note"` // Path to a file with an authors' note.
+	TreatAsHTML *bool  `toml:"treat-as-html"`
+}
+
+// Fill in the settings not given in s from defaults.
+func (s ExportSettings) withDefaults(defaults ExportSettings) ExportSettings {
+	if s.Author == "" {
+		s.Author = defaults.Author
+	}
+	if s.Copyright == "" {
+		s.Copyright = defaults.Copyright
+	}
+	if s.AuthorsNote == "" {
+		s.AuthorsNote = defaults.AuthorsNote
+	}
+	if s.TreatAsHTML == nil {
+		s.TreatAsHTML = defaults.TreatAsHTML
+	}
+	return s
+}
+
+// An export built by llex export when no format is given.
+type ExportTarget struct {
+	Format  string            `toml:"format"`
+	Output  string            `toml:"output"`
+	Columns []string          `toml:"columns"` // See ExportParams.Columns.
+	Options map[string]string `toml:"options"` // See ExportParams.Options.
+	ExportSettings
+}
+
+// The settings for a target, falling back to those shared by every target.
+func (c *Config) TargetSettings(target *ExportTarget) ExportSettings {
+	return target.ExportSettings.withDefaults(c.Export)
+}
+
+// Returned when a configuration file has settings that llex does not know about.
+type UnknownConfigKeysError struct {
+	Path string
+	Keys []string
+}
+
+func (e *UnknownConfigKeysError) Error() string {
+	return fmt.Sprintf("%s: unknown setting(s) %s", e.Path, strings.Join(e.Keys, ", "))
+}
+
+// Find the configuration file for a directory: the llex.toml file in it or in the
+// nearest directory above it. It returns "" if there is none.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Read a configuration file. Settings that llex does not know about are rejected
+// with an *UnknownConfigKeysError, since they are usually misspelt.
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{}
+	meta, err := toml.DecodeFile(path, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return nil, &UnknownConfigKeysError{Path: path, Keys: keys}
+	}
+
+	config.resolvePaths(filepath.Dir(path))
+	return config, nil
+}
+
+// Make the paths in the configuration relative to dir.
+func (c *Config) resolvePaths(dir string) {
+	resolve := func(path *string) {
+		// "-" stands for standard output.
+		if *path != "" && *path != "-" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+
+	resolve(&c.Lexicon)
+	resolve(&c.Templates.Dir)
+	resolve(&c.Templates.CSS)
+	resolve(&c.Export.Copyright)
+	resolve(&c.Export.AuthorsNote)
+	for _, target := range c.Targets {
+		resolve(&target.Output)
+		resolve(&target.Copyright)
+		resolve(&target.AuthorsNote)
+	}
+}
+
+// Apply the configuration's language settings to a dictionary, normalizing its
+// text and renumbering its homographs if they changed.
+func (c *Config) Apply(dict *Dictionary) error {
+	if c.Language.Name != "" {
+		dict.LanguageName = c.Language.Name
+	}
+	if c.Alphabet != nil {
+		dict.Alphabet = c.Alphabet
+	}
+	if c.Language.CaseSensitive != nil {
+		dict.CaseSensitive = *c.Language.CaseSensitive
+	}
+	if c.Language.Normalization != "" {
+		dict.Normalization = c.Language.Normalization
+	}
+
+	if _, err := NormalizeDictionary(dict); err != nil {
+		return fmt.Errorf("%s: %w", ConfigFileName, err)
+	}
+	AssignEntryIDs(dict)
+	ResolveRelations(dict)
+	return nil
+}
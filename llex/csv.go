@@ -0,0 +1,497 @@
+package llex
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Spreadsheet columns can only hold text, so fields with several values are
+// written as a list separated by semicolons:
+//
+//	[1] (archaic, poetic) water; [2] rain
+//
+// Each value of a sense, such as a definition or an example, starts with the sense
+// number in square brackets when the entry has more than one sense. Definitions
+// may also have qualifiers in parentheses. Examples are written as
+// "text = translation" and relations as "type: target". A backslash escapes the
+// character after it, so that values can contain any of these characters.
+// Relations to homographs have the homograph number after the headword, as in
+// "synonym: kana2". Reverse glosses start with the number of their definition and
+// a colon when their sense has several definitions, as in "[1] 2: rain", and are
+// left out on import if their sense has no definitions.
+//
+// Qualifiers of whole senses cannot be written to a column, and are lost.
+
+// Characters that must be escaped in every list value.
+const csvListChars = `\;`
+
+// A column of a CSV file and the Entry field it holds.
+type csvColumn struct {
+	name    string
+	aliases []string
+	get     func(*Entry) string
+	set     func(*Entry, string)
+	late    bool // Set after the other columns, as it refers to values they set.
+}
+
+// Columns written by ExportCSV when no columns are given.
+var defaultCSVColumns = []string{
+	"id", "word", "homograph", "partOfSpeech", "glosses", "definitions", "reverseGlosses",
+	"pronunciations", "examples", "senseNotes", "semanticDomain", "usageNotes",
+	"etymology", "borrowedWord", "literalMeaning", "variants", "relations", "date",
+}
+
+var csvColumns = []*csvColumn{
+	{
+		name: "id",
+		get:  func(e *Entry) string { return e.ID },
+		set:  func(e *Entry, v string) { e.ID = strings.TrimSpace(v) },
+	},
+	{
+		name:    "word",
+		aliases: []string{"headword", "lexeme"},
+		get:     func(e *Entry) string { return e.Word },
+		set:     func(e *Entry, v string) { e.Word = v },
+	},
+	{
+		name:    "homograph",
+		aliases: []string{"hm"},
+		get: func(e *Entry) string {
+			if e.Homograph == 0 {
+				return ""
+			}
+			return strconv.Itoa(e.Homograph)
+		},
+		// Numbers that cannot be read are left for AssignEntryIDs to fill in.
+		set: func(e *Entry, v string) { e.Homograph, _ = strconv.Atoi(strings.TrimSpace(v)) },
+	},
+	{
+		name:    "partOfSpeech",
+		aliases: []string{"pos"},
+		get:     func(e *Entry) string { return e.POS },
+		set:     func(e *Entry, v string) { e.POS = v },
+	},
+	senseStringsColumn("glosses", []string{"gloss"}, func(s *Sense) *[]string { return &s.Glosses }),
+	{
+		name:    "definitions",
+		aliases: []string{"definition"},
+		get: func(e *Entry) string {
+			return formatCSVSenses(e, func(sense *Sense, label string) []string {
+				items := make([]string, len(sense.Definitions))
+				for i, def := range sense.Definitions {
+					items[i] = formatCSVItem(label, def.Qualifiers, escapeCSVText(def.Text, ""))
+				}
+				return items
+			})
+		},
+		set: func(e *Entry, v string) {
+			for _, item := range splitCSVList(v) {
+				number, qualifiers, text := parseCSVItem(item)
+				sense := csvSense(e, number)
+				sense.Definitions = append(sense.Definitions, &Definition{
+					Qualifiers: qualifiers,
+					Text:       unescapeCSVValue(text),
+				})
+			}
+		},
+	},
+	{
+		name:    "reverseGlosses",
+		aliases: []string{"reverseGloss", "reversals"},
+		get: func(e *Entry) string {
+			return formatCSVSenses(e, func(sense *Sense, label string) []string {
+				var items []string
+				for i, def := range sense.Definitions {
+					for _, gloss := range def.ReverseGlosses {
+						text := escapeCSVText(gloss, ":")
+						if len(sense.Definitions) > 1 {
+							text = strconv.Itoa(i+1) + ": " + escapeCSVValue(gloss, ":")
+						}
+						items = append(items, formatCSVItem(label, nil, text))
+					}
+				}
+				return items
+			})
+		},
+		set: func(e *Entry, v string) {
+			for _, item := range splitCSVList(v) {
+				number, text := parseCSVSense(item)
+				n := 1
+				if parts := splitCSVEscaped(text, ':'); len(parts) > 1 {
+					if i, err := strconv.Atoi(strings.TrimSpace(parts[0])); err == nil {
+						n = i
+						text = strings.TrimSpace(strings.Join(parts[1:], ":"))
+					}
+				}
+
+				var sense *Sense
+				for _, s := range e.Senses {
+					if s.Number == number {
+						sense = s
+					}
+				}
+				if sense == nil || len(sense.Definitions) == 0 {
+					continue
+				}
+				def := sense.Definitions[min(max(n, 1), len(sense.Definitions))-1]
+				def.ReverseGlosses = append(def.ReverseGlosses, unescapeCSVValue(text))
+			}
+		},
+		late: true,
+	},
+	{
+		name:    "pronunciations",
+		aliases: []string{"pronunciation", "ipa"},
+		get: func(e *Entry) string {
+			items := make([]string, len(e.Pronunciations))
+			for i, ipa := range e.Pronunciations {
+				items[i] = formatCSVItem("", ipa.Qualifiers, escapeCSVText(ipa.Text, ""))
+			}
+			return joinCSVList(items)
+		},
+		set: func(e *Entry, v string) {
+			for _, item := range splitCSVList(v) {
+				_, qualifiers, text := parseCSVItem(item)
+				e.Pronunciations = append(e.Pronunciations, &IPA{Qualifiers: qualifiers, Text: unescapeCSVValue(text)})
+			}
+		},
+	},
+	{
+		name:    "examples",
+		aliases: []string{"example"},
+		get: func(e *Entry) string {
+			return formatCSVSenses(e, func(sense *Sense, label string) []string {
+				items := make([]string, len(sense.Examples))
+				for i, example := range sense.Examples {
+					text := escapeCSVText(example.Text, "=")
+					if example.Translation != "" {
+						text += " = " + escapeCSVValue(example.Translation, "=")
+					}
+					items[i] = formatCSVItem(label, nil, text)
+				}
+				return items
+			})
+		},
+		set: func(e *Entry, v string) {
+			for _, item := range splitCSVList(v) {
+				number, text := parseCSVSense(item)
+				parts := splitCSVEscaped(text, '=')
+				example := &Example{Text: unescapeCSVValue(strings.TrimSpace(parts[0]))}
+				if len(parts) > 1 {
+					example.Translation = unescapeCSVValue(strings.TrimSpace(strings.Join(parts[1:], "=")))
+				}
+				sense := csvSense(e, number)
+				sense.Examples = append(sense.Examples, example)
+			}
+		},
+	},
+	senseStringsColumn("senseNotes", []string{"senseNote"}, func(s *Sense) *[]string { return &s.Notes }),
+	{
+		name:    "semanticDomain",
+		aliases: []string{"semanticDomains", "domain"},
+		get: func(e *Entry) string {
+			return formatCSVSenses(e, func(sense *Sense, label string) []string {
+				if sense.SemanticDomain == "" {
+					return nil
+				}
+				return []string{formatCSVItem(label, nil, escapeCSVText(sense.SemanticDomain, ""))}
+			})
+		},
+		set: func(e *Entry, v string) {
+			for _, item := range splitCSVList(v) {
+				number, text := parseCSVSense(item)
+				csvSense(e, number).SemanticDomain = unescapeCSVValue(text)
+			}
+		},
+	},
+	{
+		name:    "usageNotes",
+		aliases: []string{"notes", "usage"},
+		get:     func(e *Entry) string { return formatCSVStrings(e.UsageNotes) },
+		set:     func(e *Entry, v string) { e.UsageNotes = append(e.UsageNotes, parseCSVStrings(v)...) },
+	},
+	{
+		name:    "etymology",
+		aliases: []string{"origin"},
+		get:     func(e *Entry) string { return e.Etymology },
+		set:     func(e *Entry, v string) { e.Etymology = v },
+	},
+	{
+		name:    "borrowedWord",
+		aliases: []string{"borrowed"},
+		get:     func(e *Entry) string { return e.BorrowedWord },
+		set:     func(e *Entry, v string) { e.BorrowedWord = v },
+	},
+	{
+		name:    "literalMeaning",
+		aliases: []string{"literal"},
+		get:     func(e *Entry) string { return e.LiteralMeaning },
+		set:     func(e *Entry, v string) { e.LiteralMeaning = v },
+	},
+	{
+		name:    "variants",
+		aliases: []string{"variant"},
+		get:     func(e *Entry) string { return formatCSVStrings(e.Variants) },
+		set:     func(e *Entry, v string) { e.Variants = append(e.Variants, parseCSVStrings(v)...) },
+	},
+	{
+		name:    "relations",
+		aliases: []string{"crossReferences"},
+		get: func(e *Entry) string {
+			items := make([]string, len(e.Relations))
+			for i, relation := range e.Relations {
+				items[i] = escapeCSVValue(relation.Type, ":") + ": " + escapeCSVValue(relation.targetHeadword(), ":")
+			}
+			return joinCSVList(items)
+		},
+		set: func(e *Entry, v string) {
+			for _, item := range splitCSVList(v) {
+				relation := &Relation{Type: RelationSeeAlso}
+				parts := splitCSVEscaped(item, ':')
+				if len(parts) > 1 {
+					relation.Type = unescapeCSVValue(strings.TrimSpace(parts[0]))
+					parts = parts[1:]
+				}
+				relation.Target = unescapeCSVValue(strings.TrimSpace(strings.Join(parts, ":")))
+				e.Relations = append(e.Relations, relation)
+			}
+		},
+	},
+	{
+		name:    "date",
+		aliases: []string{"dateModified"},
+		get:     func(e *Entry) string { return e.Date },
+		set:     func(e *Entry, v string) { e.Date = v },
+	},
+}
+
+// A column with a list of strings from each sense, such as its glosses.
+func senseStringsColumn(name string, aliases []string, field func(*Sense) *[]string) *csvColumn {
+	return &csvColumn{
+		name:    name,
+		aliases: aliases,
+		get: func(e *Entry) string {
+			return formatCSVSenses(e, func(sense *Sense, label string) []string {
+				items := make([]string, len(*field(sense)))
+				for i, value := range *field(sense) {
+					items[i] = formatCSVItem(label, nil, escapeCSVText(value, ""))
+				}
+				return items
+			})
+		},
+		set: func(e *Entry, v string) {
+			for _, item := range splitCSVList(v) {
+				number, text := parseCSVSense(item)
+				values := field(csvSense(e, number))
+				*values = append(*values, unescapeCSVValue(text))
+			}
+		},
+	}
+}
+
+// The label written before the values of a sense: its number, or its position if
+// the entry has several senses and this one has no number.
+func csvSenseLabel(e *Entry, i int) string {
+	if e.Senses[i].Number == "" && len(e.Senses) > 1 {
+		return strconv.Itoa(i + 1)
+	}
+	return e.Senses[i].Number
+}
+
+// Write the values of each sense of an entry as a single list.
+func formatCSVSenses(e *Entry, format func(sense *Sense, label string) []string) string {
+	var items []string
+	for i, sense := range e.Senses {
+		items = append(items, format(sense, csvSenseLabel(e, i))...)
+	}
+	return joinCSVList(items)
+}
+
+// The sense of an entry with the given number, which is added to the entry if it
+// does not have one yet.
+func csvSense(e *Entry, number string) *Sense {
+	for _, sense := range e.Senses {
+		if sense.Number == number {
+			return sense
+		}
+	}
+	sense := &Sense{Number: number}
+	e.Senses = append(e.Senses, sense)
+	return sense
+}
+
+// Put the senses of an imported entry in order of their numbers, since they are
+// added in the order of the first column that mentions them. Senses are left as
+// they are unless all of them have whole numbers.
+func sortCSVSenses(e *Entry) {
+	numbers := make(map[*Sense]int)
+	for _, sense := range e.Senses {
+		n, err := strconv.Atoi(sense.Number)
+		if err != nil {
+			return
+		}
+		numbers[sense] = n
+	}
+	sort.SliceStable(e.Senses, func(i, j int) bool {
+		return numbers[e.Senses[i]] < numbers[e.Senses[j]]
+	})
+}
+
+// The column names given through the Columns field or the "columns" option of
+// ImportParams or ExportParams.
+func csvColumnNames(columns []string, options map[string]string) []string {
+	if len(columns) == 0 && options["columns"] != "" {
+		return strings.Split(options["columns"], ",")
+	}
+	return columns
+}
+
+// Find a column by its name or one of its aliases, ignoring case.
+func findCSVColumn(name string) *csvColumn {
+	name = strings.TrimSpace(name)
+	for _, column := range csvColumns {
+		if strings.EqualFold(column.name, name) {
+			return column
+		}
+		for _, alias := range column.aliases {
+			if strings.EqualFold(alias, name) {
+				return column
+			}
+		}
+	}
+	return nil
+}
+
+// Escape the characters that separate list values, along with any others given.
+func escapeCSVValue(s string, separators string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(csvListChars+separators, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Like escapeCSVValue, but also escape a leading bracket or parenthesis, so that
+// the text is not read as a sense number or qualifiers.
+func escapeCSVText(s string, separators string) string {
+	s = escapeCSVValue(s, separators)
+	if strings.HasPrefix(s, "[") || strings.HasPrefix(s, "(") {
+		s = `\` + s
+	}
+	return s
+}
+
+func unescapeCSVValue(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Split a string at every unescaped occurrence of sep, keeping escapes intact.
+func splitCSVEscaped(s string, sep rune) []string {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == sep:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(parts, current.String())
+}
+
+// Split a list into its (still escaped) values, ignoring blank ones.
+func splitCSVList(s string) []string {
+	var items []string
+	for _, item := range splitCSVEscaped(s, ';') {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func joinCSVList(items []string) string {
+	return strings.Join(items, "; ")
+}
+
+// Write a list value with an optional sense number and qualifiers. The text must
+// already be escaped.
+func formatCSVItem(sense string, qualifiers []string, text string) string {
+	prefix := ""
+	if sense != "" {
+		prefix += "[" + sense + "] "
+	}
+	if len(qualifiers) > 0 {
+		escaped := make([]string, len(qualifiers))
+		for i, qualifier := range qualifiers {
+			escaped[i] = escapeCSVValue(qualifier, ",()")
+		}
+		prefix += "(" + strings.Join(escaped, ", ") + ") "
+	}
+	return prefix + text
+}
+
+// Parse the sense number at the start of a list value, returning the (still
+// escaped) remaining text.
+func parseCSVSense(item string) (sense string, text string) {
+	text = item
+	if strings.HasPrefix(text, "[") {
+		if end := strings.Index(text, "]"); end != -1 {
+			sense = strings.TrimSpace(text[1:end])
+			text = strings.TrimSpace(text[end+1:])
+		}
+	}
+	return sense, text
+}
+
+// Parse the sense number and qualifiers at the start of a list value, returning
+// the (still escaped) remaining text.
+func parseCSVItem(item string) (sense string, qualifiers []string, text string) {
+	sense, text = parseCSVSense(item)
+	if strings.HasPrefix(text, "(") {
+		if parts := splitCSVEscaped(text[1:], ')'); len(parts) > 1 {
+			for _, qualifier := range splitCSVEscaped(parts[0], ',') {
+				qualifiers = append(qualifiers, unescapeCSVValue(strings.TrimSpace(qualifier)))
+			}
+			text = strings.TrimSpace(strings.Join(parts[1:], ")"))
+		}
+	}
+	return sense, qualifiers, text
+}
+
+func formatCSVStrings(values []string) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = escapeCSVValue(value, "")
+	}
+	return joinCSVList(items)
+}
+
+func parseCSVStrings(s string) []string {
+	var values []string
+	for _, item := range splitCSVList(s) {
+		values = append(values, unescapeCSVValue(item))
+	}
+	return values
+}
@@ -0,0 +1,47 @@
+package llex
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCSVReverseGlossRoundTrip(t *testing.T) {
+	dict := &Dictionary{Entries: []*Entry{
+		{Word: "kana", POS: "n", Senses: []*Sense{
+			{Definitions: []*Definition{{Text: "water", ReverseGlosses: []string{"water: fresh"}}}},
+			{Definitions: []*Definition{
+				{Text: "rain"},
+				{Text: "a storm", ReverseGlosses: []string{"storm", "tempest"}},
+			}},
+		}},
+	}}
+	AssignEntryIDs(dict)
+
+	var exported bytes.Buffer
+	params := NewExportParams(dict)
+	params.Columns = []string{"word", "partOfSpeech", "reverseGlosses", "definitions"}
+	if err := ExportCSVTo(&exported, params); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, _, err := ImportFromCSVReader(&exported, &ImportParams{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want [][]string
+	for _, sense := range dict.Entries[0].Senses {
+		for _, def := range sense.Definitions {
+			want = append(want, def.ReverseGlosses)
+		}
+	}
+	for _, sense := range imported.Entries[0].Senses {
+		for _, def := range sense.Definitions {
+			got = append(got, def.ReverseGlosses)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reverse glosses = %q, want %q\n%s", got, want, exported.String())
+	}
+}
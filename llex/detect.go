@@ -0,0 +1,118 @@
+package llex
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Number of bytes from the start of a file that DetectImportFormat needs.
+const SniffLength = 512
+
+// Importers may implement Sniffer to recognise their format from the first bytes
+// of a file, as used by DetectImportFormat.
+type Sniffer interface {
+	Sniff(head []byte) bool
+}
+
+// Returned by DetectImportFormat when the format of a file could not be decided.
+type AmbiguousFormatError struct {
+	Filename   string
+	Candidates []string // Names of the formats the file might be in, if any.
+}
+
+func (e *AmbiguousFormatError) Error() string {
+	if len(e.Candidates) == 0 {
+		return "could not detect the format of " + e.Filename
+	}
+	return "could not tell the format of " + e.Filename + ", which could be any of: " + strings.Join(e.Candidates, ", ")
+}
+
+// Detect the import format of a file from its extension and its first bytes (at
+// least SniffLength of them, if the file is that long).
+//
+// The content of the file takes priority over its extension, and the extension is
+// only used to choose between formats that the content matches, or when no format
+// recognises the content.
+func DetectImportFormat(filename string, head []byte) (Importer, error) {
+	extension := strings.ToLower(filepath.Ext(filename))
+
+	var byExtension, byContent []Importer
+	for _, importer := range Importers() {
+		if extension != "" && slices.Contains(importer.Info().Extensions, extension) {
+			byExtension = append(byExtension, importer)
+		}
+		if sniffer, ok := importer.(Sniffer); ok && sniffer.Sniff(head) {
+			byContent = append(byContent, importer)
+		}
+	}
+
+	candidates := byExtension
+	if len(byContent) > 0 {
+		candidates = byContent
+
+		var both []Importer
+		for _, importer := range byContent {
+			if slices.Contains(byExtension, importer) {
+				both = append(both, importer)
+			}
+		}
+		if len(both) > 0 {
+			candidates = both
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	err := &AmbiguousFormatError{Filename: filename}
+	for _, importer := range candidates {
+		err.Candidates = append(err.Candidates, importer.Info().Name)
+	}
+	return nil, err
+}
+
+// The start of a file with any byte order mark and leading whitespace removed.
+func trimHead(head []byte) []byte {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	return bytes.TrimLeft(head, " \t\r\n")
+}
+
+// The first line of a file, which may be cut short.
+func firstLine(head []byte) []byte {
+	line, _, _ := bytes.Cut(trimHead(head), []byte("\n"))
+	return line
+}
+
+func sniffSFM(head []byte) bool {
+	head = trimHead(head)
+	return bytes.HasPrefix(head, []byte(`\_sh`)) || bytes.HasPrefix(head, []byte(`\lx`))
+}
+
+func sniffLIFT(head []byte) bool {
+	return bytes.Contains(head, []byte("<lift"))
+}
+
+func sniffJSON(head []byte) bool {
+	return bytes.HasPrefix(trimHead(head), []byte("{"))
+}
+
+// Whether the first line looks like a row of values separated by sep, rather
+// than the start of one of the other formats.
+func sniffDelimited(head []byte, sep byte) bool {
+	line := firstLine(head)
+	if len(line) == 0 || bytes.ContainsAny(line[:1], `\<{`) {
+		return false
+	}
+	return bytes.IndexByte(line, sep) != -1
+}
+
+func sniffCSV(head []byte) bool {
+	return sniffDelimited(head, ',')
+}
+
+func sniffTSV(head []byte) bool {
+	return sniffDelimited(head, '\t')
+}
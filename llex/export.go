@@ -0,0 +1,15 @@
+package llex
+
+import (
+	"io"
+	"strings"
+)
+
+// Run an export that writes to an io.Writer, and return its output as a string.
+func exportToString(params *ExportParams, export func(io.Writer, *ExportParams) error) (string, error) {
+	var output strings.Builder
+	if err := export(&output, params); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
@@ -0,0 +1,69 @@
+package llex
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Export a Dictionary to delimiter-separated values, one entry per row, with a
+// header naming each column. The columns are taken from params.Columns, or
+// defaultCSVColumns if none are given.
+//
+// Subentries cannot be represented in a table, so they are not exported.
+func exportDelimited(w io.Writer, params *ExportParams, comma rune) error {
+	names := csvColumnNames(params.Columns, params.Options)
+	if len(names) == 0 {
+		names = defaultCSVColumns
+	}
+
+	columns := make([]*csvColumn, len(names))
+	header := make([]string, len(names))
+	for i, name := range names {
+		columns[i] = findCSVColumn(name)
+		if columns[i] == nil {
+			return fmt.Errorf("unknown column '%s'", name)
+		}
+		header[i] = columns[i].name
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, entry := range params.Dictionary.sortedEntries() {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.get(entry)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Export a Dictionary to a CSV file. See exportDelimited.
+func ExportCSV(params *ExportParams) (string, error) {
+	return exportToString(params, ExportCSVTo)
+}
+
+// Like ExportCSV, but write the file to w.
+func ExportCSVTo(w io.Writer, params *ExportParams) error {
+	return exportDelimited(w, params, ',')
+}
+
+// Export a Dictionary to a TSV file. See exportDelimited.
+func ExportTSV(params *ExportParams) (string, error) {
+	return exportToString(params, ExportTSVTo)
+}
+
+// Like ExportTSV, but write the file to w.
+func ExportTSVTo(w io.Writer, params *ExportParams) error {
+	return exportDelimited(w, params, '\t')
+}
@@ -3,52 +3,14 @@ package llex
 import (
 	"bytes"
 	"html/template"
-	"sort"
-	"strings"
+	"io"
 	"time"
 )
 
-var CSS = `
-		.dictionary ol {
-      margin: 0px;
-    }
-		a {
-			color: #acc0fb;
-		}
-		body {
-			background-color: #000;
-			color: #fff;
-			max-width: 600px;
-			margin: auto;
-			padding: 10px;
-		}
-		@media screen {
-			body {
-				border: 1px solid #4d4d4d;
-				border-radius: 20px;
-			}
-		}
-		@media print {
-			.no-print {
-				display: none;
-			}
-		}
-		.entry {
-			break-inside: avoid;
-			margin-bottom: 8px;
-			padding-bottom: 8px;
-		}
-		.auxilliary p {
-			font-size: 85%;
-			margin: 0.1%;
-		}
-		.navbar .letter {
-			padding: 5px;
-			font-size: 110%;
-		}`
-
-// TODO: Do not hardcode "{{.LanguageName}} - English".
+// The default stylesheet for HTML exports. See ThemeCSS for the others.
+var CSS = baseCSS + darkThemeCSS
 
+// The built-in page template. See Templates.
 var HtmlTemplate = `
 <!DOCTYPE html>
 <html>
@@ -68,9 +30,13 @@ var HtmlTemplate = `
 </head>
 <body>
 		<div class="header">
-		{{if .Multipage}}<span class="no-print"><a href="./index.html">Return to index</a> or <a href="/">root page</a>.</span>{{else}}{{end}}
+		{{if .Multipage}}<span class="no-print"><a href="./index.html">Return to index</a> or <a href="/">root page</a>.{{if .ReverseIndexHref}} See the <a href="{{.ReverseIndexHref}}">{{.GlossLanguage}} index</a>.{{end}}</span>{{else}}{{end}}
     <h1><span class="language-name">{{.LanguageName}}</span></h1>
 		{{if .ShowNavbar}}{{.NavbarHTML}}{{else}}{{end}}
+		{{if .Search}}<div class="search no-print">
+		<input type="search" id="search" placeholder="Search {{.LanguageName}} or {{.GlossLanguage}}" autocomplete="off" aria-label="Search">
+		<ul id="search-results"></ul>
+		</div>{{end}}
     </div>
 		<hr>
 	{{if .AuthorsNote}}<div class="authors-note">{{.AuthorsNote}}</div>{{end}}
@@ -81,10 +47,20 @@ var HtmlTemplate = `
 	{{end}}
 	</div>
 	{{end}}
+	{{if .ReverseEntries}}
+    <div class="reverse-index" id="reverse-index">
+	<h2>{{.GlossLanguage}} - {{.LanguageName}}</h2>
+	{{range .ReverseEntries}}
+	{{.}}
+	{{end}}
+	</div>
+	{{end}}
 	{{if .IndexPage}}
-	<p>Welcome to the lexicon for {{.LanguageName}}. This is a {{.LanguageName}} - English dictionary,
-	not the other way around. To get started, click on any letter of the alphabet in the navbar.</p>
-	<p>To make searching easier, feel free to access a <a href="./all-words.html">single-page</a> version.</p>
+	<p>Welcome to the lexicon for {{.LanguageName}}. This is a {{.LanguageName}} - {{.GlossLanguage}} dictionary,
+	{{if .ReverseIndexHref}}with an <a href="{{.ReverseIndexHref}}">{{.GlossLanguage}} - {{.LanguageName}} index</a>.{{else}}not the other way around.{{end}}
+	To get started, click on any letter of the alphabet in the navbar.</p>
+	{{if .Search}}<p>To look up a word in either language, type it into the search box above. You can also browse a <a href="./all-words.html">single-page</a> version.</p>
+	{{else}}<p>To make searching easier, feel free to access a <a href="./all-words.html">single-page</a> version.</p>{{end}}
 	{{end}}
     <hr>
 	<p><b>Copyright</b>: {{.Copyright}}</p>
@@ -93,23 +69,112 @@ var HtmlTemplate = `
 	   at <span class="timestamp">{{.Timestamp}}</span>.
 	   Generation time <span class="generation-time">{{.GenerationTime}}</span>.
 	   Contains <span class="num-words">{{.NumWords}}</span> words.</p>
+	{{if .Search}}<script src="{{.SearchIndexFile}}"></script>
+	<script src="{{.SearchScriptFile}}"></script>{{end}}
 </body>
 </html>
 `
 
-var WordTemplate = `<div class="entry">
-<b><span class="headword">{{.Word}}</span></b> <i><span class="part-of-speech">{{.POS}}</span></i> <br>
-<ol class="definitions">
-{{range .Definitions}}<li class="definition">{{.Text}}</li>{{end}}
+// The built-in entry template. See Templates.
+var WordTemplate = `<div class="entry" id="entry-{{.ID}}">
+<b><span class="headword">{{.Word}}</span>{{if .Homograph}}<sup class="homograph">{{.Homograph}}</sup>{{end}}</b> <i><span class="part-of-speech">{{.POS}}</span></i> <br>
+<ol class="senses">
+{{range .Senses}}<li class="sense">{{if .Qualifiers}}<i class="qualifiers">({{range $i, $q := .Qualifiers}}{{if $i}}, {{end}}{{$q}}{{end}})</i> {{end}}{{if .Glosses}}<span class="glosses">{{range $i, $g := .Glosses}}{{if $i}}; {{end}}<span class="gloss">{{$g}}</span>{{end}}</span>{{end}}{{if and .Glosses .Definitions}}: {{end}}{{range $i, $d := .Definitions}}{{if $i}}; {{end}}<span class="definition">{{if $d.Qualifiers}}<i class="qualifiers">({{range $j, $q := $d.Qualifiers}}{{if $j}}, {{end}}{{$q}}{{end}})</i> {{end}}{{$d.Text}}</span>{{end}}{{if .SemanticDomain}} <span class="semantic-domain">[{{.SemanticDomain}}]</span>{{end}}
+{{if .Examples}}<ul class="examples">{{range .Examples}}<li class="example"><span class="example-text">{{.Text}}</span>{{if .Translation}} — <span class="example-translation">{{.Translation}}</span>{{end}}</li>{{end}}</ul>{{end}}
+{{if .Notes}}<ul class="notes">{{range .Notes}}<li class="note">{{.}}</li>{{end}}</ul>{{end}}
+</li>{{end}}
 </ol><div class="auxilliary">{{if .Etymology}}
 <p>Etymology: <span class="etymology">{{.Etymology}}</span></p>{{else}}{{end}}
 {{if .BorrowedWord}}<p>From: <span class="borrowed-from">{{.BorrowedWord}}</span></p>{{else}}{{end}}
 {{if .LiteralMeaning}}<p>Literally: "<span class="literal-meaning">{{.LiteralMeaning}}</span></p>"{{else}}{{end}}
-</div>
+{{range .Relations}}<p class="relation relation-{{.Type}}">{{relationLabel .Type}}: {{with relationHref .}}<a href="{{.}}">{{end}}<span class="relation-target">{{.Target}}</span>{{if .Homograph}}<sup class="homograph">{{.Homograph}}</sup>{{end}}{{if relationHref .}}</a>{{end}}</p>
+{{end}}</div>
+{{if .Subentries}}<div class="subentries">{{range .Subentries}}{{subentryHTML .}}{{end}}</div>{{end}}
 </div>`
 
+// Labels shown before each type of relation in HTML exports. Relations of other
+// types are labelled with their type.
+var relationLabels = map[string]string{
+	RelationSeeAlso:     "See also",
+	RelationSynonym:     "Synonym",
+	RelationAntonym:     "Antonym",
+	RelationDerivedFrom: "Derived from",
+	RelationCompoundOf:  "Compound of",
+	RelationVariantOf:   "Variant of",
+}
+
+func relationLabel(relationType string) string {
+	if label, ok := relationLabels[relationType]; ok {
+		return label
+	}
+	return relationType
+}
+
+// Works out where relations in HTML exports should link to.
+type htmlLinker struct {
+	index *entryIndex
+	// The main entry each entry is shown under, which decides the page that
+	// subentries are on.
+	main map[*Entry]*Entry
+	// The page each main entry is on, for exports with several pages. Links
+	// point to the same page if this is nil.
+	pages map[*Entry]string
+}
+
+func newHTMLLinker(dict *Dictionary, pages map[*Entry]string) *htmlLinker {
+	linker := &htmlLinker{
+		index: newEntryIndex(dict),
+		main:  make(map[*Entry]*Entry),
+		pages: pages,
+	}
+	for _, entry := range dict.Entries {
+		eachEntry([]*Entry{entry}, func(subentry *Entry) {
+			linker.main[subentry] = entry
+		})
+	}
+	return linker
+}
+
+// The link to the entry a relation refers to, or "" if it is not in the
+// dictionary. Without a linker, relations link to the anchor of their target ID on
+// the same page.
+func (l *htmlLinker) href(relation *Relation) string {
+	if l == nil {
+		if relation.TargetID == "" {
+			return ""
+		}
+		return "#entry-" + relation.TargetID
+	}
+
+	target, _ := l.index.target(relation)
+	if target == nil {
+		return ""
+	}
+	return l.entryHref(target)
+}
+
+// The link to an entry, on the page of the main entry it is shown under if it is a
+// subentry. It is "" if the entry is not on any page, as for entries without a
+// headword, which websites leave out.
+func (l *htmlLinker) entryHref(entry *Entry) string {
+	main, ok := l.main[entry]
+	if !ok {
+		return ""
+	}
+
+	page, ok := l.pages[main]
+	if !ok && l.pages != nil {
+		return ""
+	}
+	return page + "#entry-" + entry.ID
+}
+
 func (e *Entry) GenerateHTML() (string, error) {
-	t, err := template.New("html").Parse(WordTemplate)
+	return e.generateHTML(WordTemplate, nil)
+}
+
+func (e *Entry) generateHTML(source string, linker *htmlLinker) (string, error) {
+	t, err := template.New("html").Funcs(entryTemplateFuncs(source, linker)).Parse(source)
 	if err != nil {
 		return "", err
 	}
@@ -125,10 +190,10 @@ func (e *Entry) GenerateHTML() (string, error) {
 	return html.String(), nil
 }
 
-func batchGenerateEntryHTML(entries []*Entry) ([]template.HTML, error) {
+func batchGenerateEntryHTML(entries []*Entry, source string, linker *htmlLinker) ([]template.HTML, error) {
 	var entriesHTML []template.HTML
 	for _, entry := range entries {
-		entryHTML, err := entry.GenerateHTML()
+		entryHTML, err := entry.generateHTML(source, linker)
 		if err != nil {
 			return nil, err
 		}
@@ -137,44 +202,50 @@ func batchGenerateEntryHTML(entries []*Entry) ([]template.HTML, error) {
 	return entriesHTML, nil
 }
 
-func sortEntries(entries []*Entry) []*Entry {
-	sort.Slice(entries, func(i, j int) bool {
-		return strings.ToLower(entries[i].Word) < strings.ToLower(entries[j].Word)
-	})
-	return entries
-}
-
 // Export a Dictionary to a single HTML file.
 func ExportSinglePageHTML(params *ExportParams) (string, error) {
+	return exportToString(params, ExportSinglePageHTMLTo)
+}
+
+// Like ExportSinglePageHTML, but write the file to w.
+func ExportSinglePageHTMLTo(w io.Writer, params *ExportParams) error {
 	startTime := time.Now()
 	dict := params.Dictionary
 
 	var err error
 
-	sortedEntries := sortEntries(dict.Entries)
-	params.HTMLEntries, err = batchGenerateEntryHTML(sortedEntries)
+	templates := params.templates()
+	linker := newHTMLLinker(dict, nil)
+	params.HTMLEntries, err = batchGenerateEntryHTML(dict.sortedEntries(), templates.Entry, linker)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	reverseIndex, err := reverseIndexOption(params.Options)
+	if err != nil {
+		return err
+	}
+	params.ReverseEntries = nil
+	if reverseIndex {
+		params.ReverseEntries, err = generateReverseHTML(templates.Reverse, buildReverseIndex(dict, linker))
+		if err != nil {
+			return err
+		}
 	}
 
 	params.Timestamp = startTime
 	params.GenerationTime = time.Since(startTime)
 
-	return executeHTMLTemplate(params.ToTemplateParams())
+	return executeHTMLTemplate(w, templates.Page, params.TemplateData())
 }
 
-// Execute the template to create the necessary HTML with parameters already fed in.
-func executeHTMLTemplate(params map[string]any) (string, error) {
-	var html bytes.Buffer
-
-	t, err := template.New("html").Parse(HtmlTemplate)
+// Execute the page template to write the necessary HTML with parameters already
+// fed in.
+func executeHTMLTemplate(w io.Writer, source string, data *PageData) error {
+	t, err := template.New("html").Parse(source)
 	if err != nil {
-		return "", err
-	}
-
-	if err := t.Execute(&html, params); err != nil {
-		return "", err
+		return err
 	}
 
-	return html.String(), nil
+	return t.Execute(w, data)
 }
@@ -2,95 +2,183 @@ package llex
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
-	"maps"
+	"io"
 	"os"
 	"path"
-	"slices"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // Parameters for how a list of entries should be split based on their first letter.
-//
-// TODO: Allow the user to set case sensitivity instead of hardcoding it to false.
 type splitWordParams struct {
-	Entries       []*Entry // The entries to be split.
-	CaseSensitive bool     // Whether words with different casings are treated differently.
+	Entries       []*Entry  // The entries to be split.
+	CaseSensitive bool      // Whether words with different casings are treated differently.
+	Collator      *Collator // Splits words into letters, so that letters such as "ng" get their own page.
+	// Number of letters to split by: 1 for a page per letter, or 2 for a page per
+	// pair of letters, for when lexicons start to get really long.
+	PrefixLength int
 }
 
-// Get the first N unicode characters of a string. This works with Unicode strings.
-// Do not use string[0:2].
-func firstN(s string, n int) string {
-	i := 0
-	for j := range s {
-		if i == n {
-			return s[:j]
+// A page of a multi-page export, holding the entries that start with the same
+// letters.
+type letterPage struct {
+	Name    string // The letters the entries start with, which the page's file is named after. See fileName.
+	Letter  string // The first letter the entries start with.
+	Entries []*Entry
+}
+
+// The name of a page's file, given the prefix of the website's file names for
+// that kind of page. Upper case letters are written as an underscore followed by
+// the letter in lower case, and underscores are doubled. Pages whose names only
+// differ in case then still get different files on file systems that ignore case,
+// such as those of macOS and Windows.
+func (page *letterPage) fileName(prefix string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for _, r := range page.Name {
+		switch {
+		case r == '_':
+			b.WriteString("__")
+		case unicode.IsUpper(r) || unicode.IsTitle(r):
+			b.WriteRune('_')
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
 		}
-		i++
 	}
-	return s
+	b.WriteString(".html")
+	return b.String()
 }
 
-// Split a dictionary into a map of Entry slices based on the word's first letter.
-//
-// TODOs that we are not going to deal with right now because Kenahari doesn't need
-// them:
-//
-//   - The ability to split by the first two letters, for when lexicons start to get
-//     really long
-//
-func splitWordsByLetter(params *splitWordParams) map[string][]*Entry {
-	entries := params.Entries
-	alphabeticalMap := make(map[string][]*Entry)
-
-	for _, entry := range entries {
+// Split a dictionary into pages based on the first letters of each word, in
+// alphabetical order.
+func splitWordsByLetter(params *splitWordParams) []*letterPage {
+	pagesByName := make(map[string]*letterPage)
+	var pages []*letterPage
+
+	for _, entry := range params.Entries {
+		letters, cased := params.Collator.casedLetters(entry.Word)
+
 		// Ideally, empty entries (entry objects where the word is a blank string)
 		// would be caught and removed at earlier stages.
-		if entry.Word == "" {
+		if len(letters) == 0 {
 			continue
 		}
 
-		firstLetter := firstN(entry.Word, 1)
-		if !params.CaseSensitive {
-			firstLetter = strings.ToLower(firstLetter)
+		prefix := letters[:min(max(params.PrefixLength, 1), len(letters))]
+		name := strings.Join(prefix, "")
+		letter := prefix[0]
+		if params.CaseSensitive {
+			// Use the same letters as they are written in the word, to keep their
+			// case.
+			name = strings.Join(cased[:len(prefix)], "")
+			letter = cased[0]
 		}
 
-		alphabeticalMap[firstLetter] = append(alphabeticalMap[firstLetter], entry)
+		page, ok := pagesByName[name]
+		if !ok {
+			page = &letterPage{Name: name, Letter: letter}
+			pagesByName[name] = page
+			pages = append(pages, page)
+		}
+		page.Entries = append(page.Entries, entry)
 	}
 
-	return alphabeticalMap
+	sort.SliceStable(pages, func(i, j int) bool {
+		return params.Collator.Compare(pages[i].Name, pages[j].Name) < 0
+	})
+	return pages
+}
+
+// Creates a file of a multi-file export, given its path relative to the root of
+// the export.
+type CreateFunc func(name string) (io.WriteCloser, error)
+
+// A CreateFunc that creates files in a directory on disk.
+func DirCreateFunc(dir string) CreateFunc {
+	return func(name string) (io.WriteCloser, error) {
+		return os.Create(path.Join(dir, name))
+	}
 }
 
-// Convenience function to create a file with a string.
-func writeStringToFile(data string, path string) error {
-	file, err := os.Create(path)
+// A CreateFunc that keeps files in memory, storing the contents of each file in
+// files when it is closed.
+func MemoryCreateFunc(files map[string][]byte) CreateFunc {
+	return func(name string) (io.WriteCloser, error) {
+		return &memoryFile{name: name, files: files}, nil
+	}
+}
+
+type memoryFile struct {
+	bytes.Buffer
+	name  string
+	files map[string][]byte
+}
+
+func (f *memoryFile) Close() error {
+	f.files[f.name] = f.Bytes()
+	return nil
+}
+
+// Convenience function to create a file and write to it.
+func writeFile(create CreateFunc, name string, write func(io.Writer) error) error {
+	file, err := create(name)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	file.WriteString(data)
-	return nil
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Convenience function to create a file with a string.
+func writeStringToFile(create CreateFunc, name string, data string) error {
+	return writeFile(create, name, func(w io.Writer) error {
+		_, err := io.WriteString(w, data)
+		return err
+	})
 }
 
+// The built-in navbar template. See Templates.
 var navbarTemplate = `<nav class="navbar">
-{{range .Letters}}<a class="letter" id="nav-letter-{{.}}" href="./{{.}}.html">{{.}}</a>
-{{end}}</nav>`
+{{range .Letters}}<a class="letter" id="nav-letter-{{.Letter}}" href="{{.Href}}">{{.Letter}}</a>
+{{end}}</nav>{{if .Pages}}
+<nav class="navbar pages">
+{{range .Pages}}<a class="letter" id="nav-page-{{.Name}}" href="{{.Href}}">{{.Name}}</a>
+{{end}}</nav>{{end}}`
 
-func generateNavbarHtml(words *map[string][]template.HTML) (template.HTML, error) {
-	t, err := template.New("navbar").Parse(navbarTemplate)
+// The link to a page in the navigation bar, given the prefix of the page's file
+// name.
+func (page *letterPage) navbarLink(filePrefix string) *NavbarLink {
+	return &NavbarLink{Name: page.Name, Letter: page.Letter, Href: "./" + page.fileName(filePrefix)}
+}
+
+// Generate the navigation bar for a page, linking to the first page of each letter.
+// When pages are split by more than one letter, it also links to the other pages
+// for the current page's letter. The pages' file names start with filePrefix.
+func generateNavbarHtml(source string, pages []*letterPage, current *letterPage, filePrefix string) (template.HTML, error) {
+	t, err := template.New("navbar").Parse(source)
 	if err != nil {
 		return "", err
 	}
 
-	type navbarHtmlParameters struct {
-		Letters []string
-	}
-	var params navbarHtmlParameters
-	for letter := range maps.Keys(*words) {
-		params.Letters = append(params.Letters, letter)
+	var params NavbarData
+	for i, page := range pages {
+		if i == 0 || page.Letter != pages[i-1].Letter {
+			params.Letters = append(params.Letters, page.navbarLink(filePrefix))
+		}
+		if current != nil && page.Letter == current.Letter && page.Name != page.Letter {
+			params.Pages = append(params.Pages, page.navbarLink(filePrefix))
+		}
 	}
-	slices.Sort(params.Letters)
 
 	var html bytes.Buffer
 	err = t.Execute(&html, params)
@@ -101,11 +189,23 @@ func generateNavbarHtml(words *map[string][]template.HTML) (template.HTML, error
 	return template.HTML(html.String()), nil
 }
 
-// Export a Dictionary to a static set of HTML files.
+// The start of the file names of the reverse index's pages.
+const reversePagePrefix = "reverse-"
+
+// Read the "split" option, giving the number of letters to split pages by.
+func splitOption(options map[string]string) (int, error) {
+	switch options["split"] {
+	case "", "1":
+		return 1, nil
+	case "2":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("split must be 1 or 2, not '%s'", options["split"])
+}
+
+// Export a Dictionary to a static set of HTML files in params.OutputPath.
 func ExportStaticHTML(params *ExportParams) error {
-	startTime := time.Now()
 	outdir := params.OutputPath
-	CSS_FILE := "index.css"
 
 	// Create the output directory.
 	err := os.MkdirAll(outdir, 0755)
@@ -113,26 +213,95 @@ func ExportStaticHTML(params *ExportParams) error {
 		return err
 	}
 
-	// Split the entry list into individual lists by the first letter.
-	alphabeticalMap := splitWordsByLetter(&splitWordParams{
+	return ExportStaticHTMLTo(DirCreateFunc(outdir), params)
+}
+
+// Like ExportStaticHTML, but create each file with create instead of writing it
+// to params.OutputPath.
+func ExportStaticHTMLTo(create CreateFunc, params *ExportParams) error {
+	startTime := time.Now()
+	CSS_FILE := "index.css"
+
+	collator := params.Dictionary.Collator()
+	templates := params.templates()
+
+	prefixLength, err := splitOption(params.Options)
+	if err != nil {
+		return err
+	}
+
+	// Split the entry list into pages by the first letter, or letters.
+	pages := splitWordsByLetter(&splitWordParams{
 		Entries:       params.Dictionary.Entries,
-		CaseSensitive: false,
+		CaseSensitive: params.Dictionary.CaseSensitive,
+		Collator:      collator,
+		PrefixLength:  prefixLength,
 	})
 
+	// Note which page each entry is on, so that relations can link to it.
+	entryPages := make(map[*Entry]string)
+	for _, page := range pages {
+		for _, entry := range page.Entries {
+			entryPages[entry] = "./" + page.fileName("")
+		}
+	}
+	linker := newHTMLLinker(params.Dictionary, entryPages)
+
+	// Write the search index, in the same order as the pages.
+	var sortedEntries []*Entry
+	for _, page := range pages {
+		sortEntries(page.Entries, collator)
+		sortedEntries = append(sortedEntries, page.Entries...)
+	}
+	searchIndex, err := buildSearchIndex(sortedEntries, linker)
+	if err != nil {
+		return err
+	}
+	if err := writeStringToFile(create, SearchIndexFile, searchIndex); err != nil {
+		return err
+	}
+	if err := writeStringToFile(create, SearchScriptFile, SearchScript); err != nil {
+		return err
+	}
+
 	// Now, prepare the HTML strings for writing.
-	alphabeticalMapHTML := make(map[string][]template.HTML)
-	for letter, entries := range alphabeticalMap {
-		sortEntries(entries)
-		entryHTMLSlice, err := batchGenerateEntryHTML(entries)
+	pagesHTML := make(map[*letterPage][]template.HTML)
+	for _, page := range pages {
+		entryHTMLSlice, err := batchGenerateEntryHTML(page.Entries, templates.Entry, linker)
 		if err != nil {
 			return err
 		}
-		alphabeticalMapHTML[letter] = entryHTMLSlice
+		pagesHTML[page] = entryHTMLSlice
+	}
+
+	// Split the reverse index into a page per letter.
+	reverseIndex, err := reverseIndexOption(params.Options)
+	if err != nil {
+		return err
+	}
+	var reversePages []*letterPage
+	reverseEntries := make(map[*letterPage][]*ReverseIndexEntry)
+	if reverseIndex {
+		glossCollator := NewCollator(nil)
+		byLetter := make(map[string]*letterPage)
+		for _, reverse := range buildReverseIndex(params.Dictionary, linker) {
+			letter := glossCollator.FirstLetter(reverse.Key)
+			page, ok := byLetter[letter]
+			if !ok {
+				page = &letterPage{Name: letter, Letter: letter}
+				byLetter[letter] = page
+				reversePages = append(reversePages, page)
+			}
+			reverseEntries[page] = append(reverseEntries[page], reverse)
+		}
+	}
+	if len(reversePages) > 0 {
+		params.ReverseIndexHref = "./" + reversePages[0].fileName(reversePagePrefix)
 	}
 
 	// Generate the navigation bar, which will allow users to navigate
 	// by letter.
-	navbarHTML, err := generateNavbarHtml(&alphabeticalMapHTML)
+	navbarHTML, err := generateNavbarHtml(templates.Navbar, pages, nil, "")
 	if err != nil {
 		return err
 	}
@@ -140,52 +309,72 @@ func ExportStaticHTML(params *ExportParams) error {
 	params.NavbarHTML = navbarHTML
 	params.ShowNavbar = true
 	params.Multipage = true
+	params.Search = true
 	params.GenerationTime = time.Since(startTime)
 	params.IndexPage = true
 
 	// Generate index.html.
-	indexHTML, err := executeHTMLTemplate(params.ToTemplateParams())
+	templateData := params.TemplateData()
+	err = writeFile(create, "index.html", func(w io.Writer) error {
+		return executeHTMLTemplate(w, templates.Page, templateData)
+	})
 	if err != nil {
 		return err
 	}
 
 	params.IndexPage = false
 
-	err = writeStringToFile(indexHTML, path.Join(outdir, "index.html"))
-	if err != nil {
-		return err
-	}
-
 	// Begin generating the HTML pages.
-	for letter, entries := range alphabeticalMapHTML {
-		params.HTMLEntries = entries
-		params.NumWords = len(entries)
-		htmlString, err := executeHTMLTemplate(params.ToTemplateParams())
+	for _, page := range pages {
+		params.NavbarHTML, err = generateNavbarHtml(templates.Navbar, pages, page, "")
 		if err != nil {
 			return err
 		}
+		params.HTMLEntries = pagesHTML[page]
+		params.NumWords = len(page.Entries)
+		templateData := params.TemplateData()
+		err = writeFile(create, page.fileName(""), func(w io.Writer) error {
+			return executeHTMLTemplate(w, templates.Page, templateData)
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-		err = writeStringToFile(htmlString, path.Join(outdir, letter+".html"))
+	// Then the pages of the reverse index, which have their own navigation bar.
+	for _, page := range reversePages {
+		params.NavbarHTML, err = generateNavbarHtml(templates.Navbar, reversePages, page, reversePagePrefix)
+		if err != nil {
+			return err
+		}
+		params.HTMLEntries = nil
+		params.ReverseEntries, err = generateReverseHTML(templates.Reverse, reverseEntries[page])
+		if err != nil {
+			return err
+		}
+		params.NumWords = len(reverseEntries[page])
+		templateData := params.TemplateData()
+		err = writeFile(create, page.fileName(reversePagePrefix), func(w io.Writer) error {
+			return executeHTMLTemplate(w, templates.Page, templateData)
+		})
 		if err != nil {
 			return err
 		}
 	}
 
 	// Write the CSS file out.
-	err = writeStringToFile(CSS, path.Join(outdir, CSS_FILE))
+	err = writeStringToFile(create, CSS_FILE, string(params.stylesheet()))
 	if err != nil {
 		return err
 	}
 
 	params.CSSFile = CSS_FILE
 	params.Multipage = true
+	params.NavbarHTML = navbarHTML
 	params.NumWords = len(params.Dictionary.Entries)
 
 	// Generate the all-words.html file.
-	allHTML, err := ExportSinglePageHTML(params)
-	if err != nil {
-		return err
-	}
-
-	return writeStringToFile(allHTML, path.Join(outdir, "all-words.html"))
+	return writeFile(create, "all-words.html", func(w io.Writer) error {
+		return ExportSinglePageHTMLTo(w, params)
+	})
 }
@@ -0,0 +1,87 @@
+package llex
+
+import (
+	"strings"
+	"testing"
+)
+
+// Pages whose names only differ in case must not be written to files that would
+// overwrite each other on file systems that ignore case.
+func TestStaticHTMLPageFilesIgnoringCase(t *testing.T) {
+	dict := &Dictionary{
+		LanguageName:  "Test",
+		CaseSensitive: true,
+		Entries: []*Entry{
+			{Word: "Apa", Senses: []*Sense{{Glosses: []string{"father"}}}},
+			{Word: "apa", Senses: []*Sense{{Glosses: []string{"what"}}}},
+			{Word: "_apa", Senses: []*Sense{{Glosses: []string{"under"}}}},
+		},
+	}
+	AssignEntryIDs(dict)
+
+	files := make(map[string][]byte)
+	if err := ExportStaticHTMLTo(MemoryCreateFunc(files), NewExportParams(dict)); err != nil {
+		t.Fatal(err)
+	}
+
+	folded := make(map[string]string)
+	for name := range files {
+		if other, ok := folded[strings.ToLower(name)]; ok {
+			t.Errorf("%s and %s are the same file when case is ignored", name, other)
+		}
+		folded[strings.ToLower(name)] = name
+	}
+
+	for _, name := range []string{"_a.html", "a.html", "__.html"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("no page %s was written", name)
+		}
+	}
+}
+
+func TestSplitWordsByLetterKeepsCase(t *testing.T) {
+	collator := NewCollator(&Alphabet{Letters: []string{"a", "i", "ng", "t"}, Ignorable: []string{"-"}})
+	entries := []*Entry{
+		{Word: "-ta"},
+		{Word: "Ta"},
+		{Word: "Nga"},
+		// Lower case İ is written with two characters.
+		{Word: "İta"},
+	}
+
+	var names []string
+	for _, page := range splitWordsByLetter(&splitWordParams{Entries: entries, CaseSensitive: true, Collator: collator, PrefixLength: 1}) {
+		names = append(names, page.Name)
+	}
+
+	want := []string{"İ", "Ng", "T", "t"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("got pages %v, want %v", names, want)
+	}
+}
+
+// Subentries are indexed with links to their own anchors, which must be on the
+// page of their main entry.
+func TestStaticHTMLSubentryAnchors(t *testing.T) {
+	dict := &Dictionary{
+		LanguageName: "Test",
+		Entries: []*Entry{
+			{Word: "bat", Senses: []*Sense{{Glosses: []string{"flying mammal"}}}, Subentries: []*Entry{
+				{Word: "bat-bat", Senses: []*Sense{{Glosses: []string{"colony"}}}},
+			}},
+		},
+	}
+	AssignEntryIDs(dict)
+	files := make(map[string][]byte)
+	if err := ExportStaticHTMLTo(MemoryCreateFunc(files), NewExportParams(dict)); err != nil {
+		t.Fatal(err)
+	}
+
+	anchor := `id="entry-bat-bat"`
+	if !strings.Contains(string(files["b.html"]), anchor) {
+		t.Errorf("b.html has no %s", anchor)
+	}
+	if !strings.Contains(string(files[SearchIndexFile]), "./b.html#entry-bat-bat") {
+		t.Errorf("the search index does not link to the subentry")
+	}
+}
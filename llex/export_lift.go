@@ -0,0 +1,247 @@
+package llex
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of the dates entries are commonly given, such as the MDF "12/Mar/2024".
+var liftDateLayouts = []string{
+	"2006-01-02",
+	"02/Jan/2006",
+	"2/Jan/2006",
+	"02/Jan/06",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// Convert an entry's date to the ISO 8601 form LIFT requires, or return "" if it
+// cannot be read.
+func liftDate(date string) string {
+	date = strings.TrimSpace(date)
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	for _, layout := range liftDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// Converts llex entries to LIFT entries.
+type liftExporter struct {
+	document  *liftDocument
+	ids       map[*Entry]string
+	headwords map[headwordKey]string // LIFT ID of the first entry with each headword and homograph number.
+}
+
+// Assign LIFT IDs to entries and their subentries. Entries keep their own IDs if
+// they have them.
+func (ex *liftExporter) assignIDs(entries []*Entry, prefix string) {
+	for i, entry := range entries {
+		id := prefix + strconv.Itoa(i+1)
+		if prefix == "" {
+			id = entry.Word + "_" + id
+		}
+		if entry.ID != "" {
+			id = entry.ID
+		}
+
+		ex.ids[entry] = id
+		key := headwordKey{entry.Word, entry.Homograph}
+		if _, ok := ex.headwords[key]; !ok {
+			ex.headwords[key] = id
+		}
+
+		ex.assignIDs(entry.Subentries, id+"_se")
+	}
+}
+
+func (ex *liftExporter) relation(relation *Relation) *liftRelation {
+	relationType := relation.Type
+	if liftType, ok := liftRelationTypes[relationType]; ok {
+		relationType = liftType
+	}
+
+	// Fall back to the headword itself so that dangling references are not lost.
+	ref, ok := ex.headwords[headwordKey{relation.Target, relation.Homograph}]
+	if !ok {
+		ref = relation.targetHeadword()
+	}
+
+	return &liftRelation{Type: relationType, Ref: ref}
+}
+
+func (ex *liftExporter) sense(sense *Sense, id string, pos string) *liftSense {
+	lift := &liftSense{ID: id}
+	if pos != "" {
+		lift.GrammaticalInfo = &liftTrait{Value: pos}
+	}
+
+	for _, gloss := range sense.Glosses {
+		lift.Glosses = append(lift.Glosses, liftForm{Lang: liftAnalysisLang, Text: gloss})
+	}
+
+	if len(sense.Definitions) > 0 {
+		text := sense.Definitions[0].Text
+		for _, def := range sense.Definitions[1:] {
+			text += "; " + def.Text
+		}
+		lift.Definition = newLiftMultiText(liftAnalysisLang, text)
+	}
+
+	for _, def := range sense.Definitions {
+		for _, gloss := range def.ReverseGlosses {
+			lift.Reversals = append(lift.Reversals, &liftReversal{
+				Type:          liftAnalysisLang,
+				liftMultiText: *newLiftMultiText(liftAnalysisLang, gloss),
+			})
+		}
+	}
+
+	for _, example := range sense.Examples {
+		liftExample := &liftExample{liftMultiText: *newLiftMultiText(liftVernacularLang, example.Text)}
+		if example.Translation != "" {
+			liftExample.Translations = append(liftExample.Translations, newLiftMultiText(liftAnalysisLang, example.Translation))
+		}
+		lift.Examples = append(lift.Examples, liftExample)
+	}
+
+	for _, note := range sense.Notes {
+		lift.Notes = append(lift.Notes, &liftNote{liftMultiText: *newLiftMultiText(liftAnalysisLang, note)})
+	}
+
+	for _, qualifier := range sense.Qualifiers {
+		lift.Traits = append(lift.Traits, &liftTrait{Name: liftUsageTypeTrait, Value: qualifier})
+	}
+	for i, def := range sense.Definitions {
+		for _, qualifier := range def.Qualifiers {
+			lift.Traits = append(lift.Traits, &liftTrait{Name: liftDefinitionQualifierTrait + strconv.Itoa(i+1), Value: qualifier})
+		}
+	}
+	if sense.SemanticDomain != "" {
+		lift.Traits = append(lift.Traits, &liftTrait{Name: liftSemanticDomainTrait, Value: sense.SemanticDomain})
+	}
+
+	return lift
+}
+
+// Add an entry, followed by its subentries, to the document.
+func (ex *liftExporter) entry(entry *Entry) *liftEntry {
+	id := ex.ids[entry]
+	lift := &liftEntry{
+		ID:           id,
+		Order:        entry.Homograph,
+		DateModified: liftDate(entry.Date),
+		LexicalUnit:  newLiftMultiText(liftVernacularLang, entry.Word),
+	}
+
+	for _, ipa := range entry.Pronunciations {
+		pronunciation := &liftIPA{liftMultiText: *newLiftMultiText(liftVernacularLang+"-fonipa", ipa.Text)}
+		for _, qualifier := range ipa.Qualifiers {
+			pronunciation.Traits = append(pronunciation.Traits, &liftTrait{Name: liftUsageTypeTrait, Value: qualifier})
+		}
+		lift.Pronunciations = append(lift.Pronunciations, pronunciation)
+	}
+
+	for _, variant := range entry.Variants {
+		lift.Variants = append(lift.Variants, newLiftMultiText(liftVernacularLang, variant))
+	}
+
+	senses := entry.Senses
+	if len(senses) == 0 && entry.POS != "" {
+		// Keep the part of speech of entries without senses.
+		senses = []*Sense{{}}
+	}
+
+	for i, sense := range senses {
+		lift.Senses = append(lift.Senses, ex.sense(sense, id+"_"+strconv.Itoa(i+1), entry.POS))
+	}
+
+	for _, note := range entry.UsageNotes {
+		lift.Notes = append(lift.Notes, &liftNote{Type: "usage", liftMultiText: *newLiftMultiText(liftAnalysisLang, note)})
+	}
+
+	for _, relation := range entry.Relations {
+		lift.Relations = append(lift.Relations, ex.relation(relation))
+	}
+
+	if entry.Etymology != "" {
+		lift.Etymologies = append(lift.Etymologies, &liftEtymology{
+			Type:          "proto",
+			liftMultiText: *newLiftMultiText(liftAnalysisLang, entry.Etymology),
+		})
+	}
+	if entry.BorrowedWord != "" {
+		lift.Etymologies = append(lift.Etymologies, &liftEtymology{
+			Type:          "borrowed",
+			liftMultiText: *newLiftMultiText(liftAnalysisLang, entry.BorrowedWord),
+		})
+	}
+
+	if entry.LiteralMeaning != "" {
+		lift.Fields = append(lift.Fields, &liftField{
+			Type:          "literal-meaning",
+			liftMultiText: *newLiftMultiText(liftAnalysisLang, entry.LiteralMeaning),
+		})
+	}
+
+	ex.document.Entries = append(ex.document.Entries, lift)
+
+	// LIFT has no nesting, so subentries follow their main entry and link to it.
+	for _, subentry := range entry.Subentries {
+		sub := ex.entry(subentry)
+		sub.Relations = append(sub.Relations, &liftRelation{Type: liftComponentRelation, Ref: id})
+	}
+
+	return lift
+}
+
+// Export a Dictionary to a LIFT file, for use with FieldWorks (FLEx) and WeSay.
+//
+// Sense numbers are not exported, as LIFT numbers senses by their order. Dates are
+// converted to ISO 8601, and left out if they cannot be read. Qualifiers of senses
+// and pronunciations are written as usage-type traits, and those of definitions as
+// definition-qualifier traits of their sense, which ImportFromLIFT reads back.
+// Reverse glosses are written as reversals of their sense, and so are read back as
+// those of the sense's first definition.
+// Subentries are exported as separate entries linked to their main entry.
+func ExportLIFT(params *ExportParams) (string, error) {
+	return exportToString(params, ExportLIFTTo)
+}
+
+// Like ExportLIFT, but write the file to w.
+func ExportLIFTTo(w io.Writer, params *ExportParams) error {
+	ex := &liftExporter{
+		document:  &liftDocument{Version: liftVersion, Producer: "lemurian-lexicon-manager"},
+		ids:       make(map[*Entry]string),
+		headwords: make(map[headwordKey]string),
+	}
+
+	entries := params.Dictionary.sortedEntries()
+	ex.assignIDs(entries, "")
+
+	for _, entry := range entries {
+		ex.entry(entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(ex.document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,92 @@
+package llex
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestLiftDate(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"12/Mar/2024", "2024-03-12"},
+		{"2/Mar/2024", "2024-03-02"},
+		{"2024-03-12", "2024-03-12"},
+		{"2024-03-12T10:30:00+02:00", "2024-03-12T08:30:00Z"},
+		{"March 12, 2024", "2024-03-12"},
+		{"last Tuesday", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := liftDate(test.date); got != test.want {
+			t.Errorf("liftDate(%q) = %q, want %q", test.date, got, test.want)
+		}
+	}
+}
+
+// Qualifiers have no place of their own in LIFT, but should survive a round trip
+// through ExportLIFT and ImportFromLIFT.
+func TestLiftQualifierRoundTrip(t *testing.T) {
+	dict := &Dictionary{Entries: []*Entry{{
+		Word:           "kana",
+		POS:            "n",
+		Pronunciations: []*IPA{{Text: "ˈkana", Qualifiers: []string{"northern"}}},
+		Senses: []*Sense{{
+			Qualifiers: []string{"archaic"},
+			Definitions: []*Definition{
+				{Text: "water"},
+				{Text: "rain", Qualifiers: []string{"poetic", "rare"}},
+			},
+		}},
+	}}}
+	AssignEntryIDs(dict)
+
+	var exported bytes.Buffer
+	if err := ExportLIFTTo(&exported, NewExportParams(dict)); err != nil {
+		t.Fatal(err)
+	}
+	imported, _, err := ImportFromLIFTReader(&exported, &ImportParams{Strict: true})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, exported.String())
+	}
+
+	entry := imported.Entries[0]
+	if got := entry.Pronunciations[0].Qualifiers; !slices.Equal(got, []string{"northern"}) {
+		t.Errorf("pronunciation qualifiers = %v", got)
+	}
+	sense := entry.Senses[0]
+	if !slices.Equal(sense.Qualifiers, []string{"archaic"}) {
+		t.Errorf("sense qualifiers = %v", sense.Qualifiers)
+	}
+	if len(sense.Definitions[0].Qualifiers) != 0 || !slices.Equal(sense.Definitions[1].Qualifiers, []string{"poetic", "rare"}) {
+		t.Errorf("definition qualifiers = %v and %v", sense.Definitions[0].Qualifiers, sense.Definitions[1].Qualifiers)
+	}
+}
+
+func TestLiftReversalRoundTrip(t *testing.T) {
+	dict := &Dictionary{Entries: []*Entry{{
+		Word: "kana",
+		POS:  "n",
+		Senses: []*Sense{{Definitions: []*Definition{
+			{Text: "fresh water", ReverseGlosses: []string{"water", "fresh water"}},
+		}}},
+	}}}
+	AssignEntryIDs(dict)
+
+	var exported bytes.Buffer
+	if err := ExportLIFTTo(&exported, NewExportParams(dict)); err != nil {
+		t.Fatal(err)
+	}
+	imported, _, err := ImportFromLIFTReader(&exported, &ImportParams{Strict: true})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, exported.String())
+	}
+
+	got := imported.Entries[0].Senses[0].Definitions[0].ReverseGlosses
+	if want := []string{"water", "fresh water"}; !slices.Equal(got, want) {
+		t.Errorf("reverse glosses = %v, want %v\n%s", got, want, exported.String())
+	}
+}
@@ -0,0 +1,145 @@
+package llex
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Header written at the top of exported SFM files, so that Toolbox recognises them
+// as MDF dictionaries.
+const sfmHeader = `\_sh v3.0  400  MDF 4.0`
+
+// Markers for each relation type, the reverse of lexiqueProRelations.
+func sfmRelationMarker(relationType string) (string, bool) {
+	for marker, t := range lexiqueProRelations {
+		if t == relationType {
+			return marker, true
+		}
+	}
+	return "", false
+}
+
+// Writes SFM fields.
+type sfmWriter struct {
+	builder strings.Builder
+}
+
+func (w *sfmWriter) field(marker string, value string) {
+	w.builder.WriteString(marker)
+	if value != "" {
+		w.builder.WriteString(" " + value)
+	}
+	w.builder.WriteString("\n")
+}
+
+// Write a sense. \sn is written when the sense has a number, and for every sense
+// after the first, so that ImportFromLexiquePro can tell them apart.
+func (w *sfmWriter) sense(sense *Sense, first bool) {
+	if sense.Number != "" || !first {
+		w.field(`\sn`, sense.Number)
+	}
+	for _, qualifier := range sense.Qualifiers {
+		w.field(`\zsq`, qualifier)
+	}
+	for _, gloss := range sense.Glosses {
+		w.field(`\ge`, gloss)
+	}
+	for _, def := range sense.Definitions {
+		w.field(`\de`, def.Text)
+		for _, qualifier := range def.Qualifiers {
+			w.field(`\zdq`, qualifier)
+		}
+		for _, gloss := range def.ReverseGlosses {
+			w.field(`\re`, gloss)
+		}
+	}
+	for _, example := range sense.Examples {
+		w.field(`\xv`, example.Text)
+		if example.Translation != "" {
+			w.field(`\xe`, example.Translation)
+		}
+	}
+	for _, note := range sense.Notes {
+		w.field(`\nt`, note)
+	}
+	if sense.SemanticDomain != "" {
+		w.field(`\sd`, sense.SemanticDomain)
+	}
+}
+
+// Write an entry, starting with the given record marker (\lx or \se).
+func (w *sfmWriter) entry(marker string, entry *Entry) {
+	w.field(marker, entry.Word)
+	if entry.Homograph > 0 {
+		w.field(`\hm`, strconv.Itoa(entry.Homograph))
+	}
+
+	for _, ipa := range entry.Pronunciations {
+		w.field(`\ph`, ipa.Text)
+		for _, qualifier := range ipa.Qualifiers {
+			w.field(`\zpq`, qualifier)
+		}
+	}
+	if entry.POS != "" {
+		w.field(`\ps`, entry.POS)
+	}
+	// Usage notes come before the senses, as notes after them belong to a sense.
+	for _, note := range entry.UsageNotes {
+		w.field(`\nt`, note)
+	}
+	for i, sense := range entry.Senses {
+		w.sense(sense, i == 0)
+	}
+	for _, variant := range entry.Variants {
+		w.field(`\va`, variant)
+	}
+	for _, relation := range entry.Relations {
+		if relationMarker, ok := sfmRelationMarker(relation.Type); ok {
+			w.field(relationMarker, relation.targetHeadword())
+		} else {
+			w.field(`\lf`, relation.Type+" = "+relation.targetHeadword())
+		}
+	}
+	if entry.Etymology != "" {
+		w.field(`\et`, entry.Etymology)
+	}
+	if entry.BorrowedWord != "" {
+		w.field(`\bw`, entry.BorrowedWord)
+	}
+	if entry.LiteralMeaning != "" {
+		w.field(`\lt`, entry.LiteralMeaning)
+	}
+	if entry.Date != "" {
+		w.field(`\dt`, entry.Date)
+	}
+
+	for _, subentry := range entry.Subentries {
+		w.entry(`\se`, subentry)
+	}
+}
+
+// Export a Dictionary to a Standard Format Marker (MDF) file that can be opened by
+// Lexique Pro and Toolbox, and imported again with ImportFromLexiquePro.
+//
+// MDF has no markers for qualifiers, so they are written to the \zsq, \zdq and
+// \zpq markers that ImportFromLexiquePro reads. Each definition and gloss is
+// written to its own \de or \ge field, so they must not contain semicolons if the
+// file is to be imported again unchanged.
+func ExportSFM(params *ExportParams) (string, error) {
+	return exportToString(params, ExportSFMTo)
+}
+
+// Like ExportSFM, but write the file to w.
+func ExportSFMTo(w io.Writer, params *ExportParams) error {
+	var sfm sfmWriter
+	sfm.builder.WriteString(sfmHeader + "\n")
+
+	for _, entry := range params.Dictionary.sortedEntries() {
+		sfm.builder.WriteString("\n")
+		sfm.entry(`\lx`, entry)
+	}
+
+	_, err := io.WriteString(w, sfm.builder.String())
+	return err
+}
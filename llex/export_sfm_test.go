@@ -0,0 +1,64 @@
+package llex
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Importing an SFM file, exporting it and importing it again should give the same
+// dictionary, so that no field is lost on the way through MDF.
+func TestSFMRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/roundtrip.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := &ImportParams{Filename: "roundtrip.db", Strict: true}
+	imported, _, err := ImportFromLexiqueProReader(bytes.NewReader(data), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exported bytes.Buffer
+	if err := ExportSFMTo(&exported, NewExportParams(imported)); err != nil {
+		t.Fatal(err)
+	}
+
+	reimported, _, err := ImportFromLexiqueProReader(&exported, params)
+	if err != nil {
+		t.Fatalf("importing the exported file: %v\n%s", err, exported.String())
+	}
+
+	if !reflect.DeepEqual(imported, reimported) {
+		before, _ := FormatDictionary(imported)
+		after, _ := FormatDictionary(reimported)
+		t.Errorf("dictionary changed on the way through SFM:\nbefore:\n%s\nafter:\n%s\nexported:\n%s", before, after, exported.String())
+	}
+}
+
+// The fixture should use every marker the importer understands, so that the round
+// trip covers all of them.
+func TestSFMRoundTripFixtureCoversMarkers(t *testing.T) {
+	file, err := os.Open("testdata/roundtrip.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	fields, err := readSFMFields(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used := make(map[string]bool)
+	for _, field := range fields {
+		used[field.Marker] = true
+	}
+	for marker := range lexiqueProMarkers {
+		if !used[marker] {
+			t.Errorf("testdata/roundtrip.db does not use %s", marker)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package llex
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Call fn for each entry and subentry, in order.
+func eachEntry(entries []*Entry, fn func(entry *Entry)) {
+	for _, entry := range entries {
+		fn(entry)
+		eachEntry(entry.Subentries, fn)
+	}
+}
+
+// A headword along with its homograph number, which together identify an entry.
+type headwordKey struct {
+	word      string
+	homograph int
+}
+
+// Make an ID from a headword: its letters and digits in lower case, with
+// everything else replaced by hyphens.
+func slugify(word string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(word) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		hyphen = true
+	}
+	if b.Len() == 0 {
+		return "entry"
+	}
+	return b.String()
+}
+
+// Number the entries that share a headword. Existing numbers are kept unless two
+// entries with the same headword have the same number, and entries with no other
+// entries sharing their headword have no number. Headwords are compared as
+// described by Dictionary.foldWord.
+func assignHomographs(dict *Dictionary) {
+	var words []string
+	byWord := make(map[string][]*Entry)
+	eachEntry(dict.Entries, func(entry *Entry) {
+		word := dict.foldWord(entry.Word)
+		if _, ok := byWord[word]; !ok {
+			words = append(words, word)
+		}
+		byWord[word] = append(byWord[word], entry)
+	})
+
+	for _, word := range words {
+		homographs := byWord[word]
+		if len(homographs) == 1 {
+			homographs[0].Homograph = 0
+			continue
+		}
+
+		used := make(map[int]bool)
+		var unnumbered []*Entry
+		for _, entry := range homographs {
+			if entry.Homograph > 0 && !used[entry.Homograph] {
+				used[entry.Homograph] = true
+				continue
+			}
+			unnumbered = append(unnumbered, entry)
+		}
+
+		next := 1
+		for _, entry := range unnumbered {
+			for used[next] {
+				next++
+			}
+			entry.Homograph = next
+			used[next] = true
+		}
+	}
+}
+
+// Give each entry without an ID, or with the same ID as an earlier entry, an ID
+// made from its headword and homograph number.
+func assignIDs(entries []*Entry) {
+	used := make(map[string]bool)
+	var missing []*Entry
+	eachEntry(entries, func(entry *Entry) {
+		if entry.ID != "" && !used[entry.ID] {
+			used[entry.ID] = true
+			return
+		}
+		missing = append(missing, entry)
+	})
+
+	for _, entry := range missing {
+		base := slugify(entry.Word)
+		if entry.Homograph > 0 {
+			base += "-" + strconv.Itoa(entry.Homograph)
+		}
+
+		id := base
+		for n := 2; used[id]; n++ {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		entry.ID = id
+		used[id] = true
+	}
+}
+
+// Give every entry and subentry that shares its headword with another entry a
+// homograph number, and every one without an ID a unique ID. Existing homograph
+// numbers and IDs are kept, unless another entry already has the same one, so IDs
+// stay the same as the lexicon is edited.
+//
+// Dictionaries returned by ReadDictionary and by importers have already been
+// through AssignEntryIDs.
+func AssignEntryIDs(dict *Dictionary) {
+	assignHomographs(dict)
+	assignIDs(dict.Entries)
+}
+
+// Split a homograph number off the end of a headword, as in "kana2" or "kana²".
+func splitHomograph(word string) (string, int) {
+	runes := []rune(word)
+	number := 0
+	place := 1
+	i := len(runes)
+	for ; i > 0; i-- {
+		digit := strings.IndexRune("0123456789", runes[i-1])
+		if digit == -1 {
+			digit = strings.IndexRune("⁰¹²³⁴⁵⁶⁷⁸⁹", runes[i-1])
+		}
+		if digit == -1 {
+			break
+		}
+		number += digit * place
+		place *= 10
+	}
+	if i == 0 || i == len(runes) {
+		return word, 0
+	}
+	return string(runes[:i]), number
+}
+
+// The target of a relation as written in formats without a separate homograph
+// number, such as MDF: the headword followed by the homograph number.
+func (r *Relation) targetHeadword() string {
+	if r.Homograph > 0 {
+		return r.Target + strconv.Itoa(r.Homograph)
+	}
+	return r.Target
+}
+
+// Split homograph numbers off relation targets written as part of the headword,
+// such as "kana2", where there is such a homograph and no entry with the whole
+// target as its headword.
+func resolveRelationHomographs(dict *Dictionary) {
+	words := make(map[string]bool)
+	homographs := make(map[headwordKey]bool)
+	eachEntry(dict.Entries, func(entry *Entry) {
+		word := dict.foldWord(entry.Word)
+		words[word] = true
+		homographs[headwordKey{word, entry.Homograph}] = true
+	})
+
+	eachEntry(dict.Entries, func(entry *Entry) {
+		for _, relation := range entry.Relations {
+			if relation.Homograph > 0 || words[dict.foldWord(relation.Target)] {
+				continue
+			}
+			word, number := splitHomograph(relation.Target)
+			if number > 0 && homographs[headwordKey{dict.foldWord(word), number}] {
+				relation.Target = word
+				relation.Homograph = number
+			}
+		}
+	})
+}
+
+// Apply the import's text settings to an imported dictionary, number homographs,
+// assign IDs to its entries, and link relations to them.
+func finishImport(dict *Dictionary, params *ImportParams) error {
+	dict.CaseSensitive = params.CaseSensitive
+	dict.Normalization = params.Normalization
+	if _, err := NormalizeDictionary(dict); err != nil {
+		return err
+	}
+
+	assignHomographs(dict)
+	resolveRelationHomographs(dict)
+	assignIDs(dict.Entries)
+	ResolveRelations(dict)
+	return nil
+}
@@ -0,0 +1,76 @@
+package llex
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Parameters for importing a dictionary from another format.
+type ImportParams struct {
+	Filename string   // The file to import from.
+	Strict   bool     // Fail on anything that cannot be imported, instead of skipping it.
+	Columns  []string // The field in each column, for tabular formats such as CSV.
+
+	// Text settings for the imported dictionary. See Dictionary.CaseSensitive and
+	// Dictionary.Normalization.
+	CaseSensitive bool
+	Normalization string
+
+	// Options specific to the format being imported, as listed in its FormatInfo.
+	Options map[string]string
+}
+
+// A problem with a specific line of an imported file.
+type ImportError struct {
+	File   string
+	Line   int
+	Marker string // The marker on the offending line, if the format has markers.
+	Reason string
+}
+
+func (e *ImportError) Error() string {
+	location := e.File
+	if e.Line != 0 {
+		location += fmt.Sprintf(":%d", e.Line)
+	}
+	if e.Marker != "" {
+		return location + ": " + e.Marker + ": " + e.Reason
+	}
+	return location + ": " + e.Reason
+}
+
+// Details about an import that succeeded, but may not have imported everything.
+type ImportReport struct {
+	Skipped []*ImportError // Parts of the file that were not imported, in file order.
+}
+
+// Count how many times each marker was skipped.
+func (r *ImportReport) SkippedMarkers() map[string]int {
+	counts := make(map[string]int)
+	for _, skipped := range r.Skipped {
+		counts[skipped.Marker]++
+	}
+	return counts
+}
+
+// Record a part of the file that could not be imported. In strict mode, the
+// problem is returned as an error instead.
+func (r *ImportReport) skip(params *ImportParams, err *ImportError) error {
+	if params.Strict {
+		return err
+	}
+	r.Skipped = append(r.Skipped, err)
+	return nil
+}
+
+// Open params.Filename and import it with the given function.
+func importFile(params *ImportParams, importFn func(io.Reader, *ImportParams) (*Dictionary, *ImportReport, error)) (*Dictionary, *ImportReport, error) {
+	file, err := os.Open(params.Filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return importFn(file, params)
+}
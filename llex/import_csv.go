@@ -0,0 +1,116 @@
+package llex
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// Import a dictionary from a file of delimiter-separated values, one entry per row.
+//
+// The first row is a header naming the field in each column. params.Columns
+// overrides the header, and a blank or "-" column name ignores that column.
+func importDelimited(r io.Reader, params *ImportParams, comma rune) (*Dictionary, *ImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	// Tab-separated files are rarely quoted, so quotes in them are usually literal.
+	reader.LazyQuotes = comma == '\t'
+
+	dictionary := &Dictionary{Entries: make([]*Entry, 0)}
+	report := &ImportReport{}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return dictionary, report, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := csvColumnNames(params.Columns, params.Options)
+	if len(names) == 0 {
+		names = header
+	}
+
+	columns := make([]*csvColumn, len(names))
+	for i, name := range names {
+		if name = strings.TrimSpace(name); name == "" || name == "-" {
+			continue
+		}
+
+		columns[i] = findCSVColumn(name)
+		if columns[i] == nil {
+			err := report.skip(params, &ImportError{File: params.Filename, Marker: name, Reason: "unknown column"})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		entry := &Entry{Senses: make([]*Sense, 0)}
+		for i, value := range record {
+			if i < len(columns) && columns[i] != nil {
+				if !columns[i].late {
+					columns[i].set(entry, value)
+				}
+				continue
+			}
+
+			if i >= len(names) && value != "" {
+				line, _ := reader.FieldPos(i)
+				err := report.skip(params, &ImportError{File: params.Filename, Line: line, Reason: "value in a column without a name"})
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+
+		for i, value := range record {
+			if i < len(columns) && columns[i] != nil && columns[i].late {
+				columns[i].set(entry, value)
+			}
+		}
+
+		sortCSVSenses(entry)
+		dictionary.Entries = append(dictionary.Entries, entry)
+	}
+
+	if err := finishImport(dictionary, params); err != nil {
+		return nil, nil, err
+	}
+	return dictionary, report, nil
+}
+
+// Like ImportFromCSV, but read the file from r.
+func ImportFromCSVReader(r io.Reader, params *ImportParams) (*Dictionary, *ImportReport, error) {
+	return importDelimited(r, params, ',')
+}
+
+// Like ImportFromTSV, but read the file from r.
+func ImportFromTSVReader(r io.Reader, params *ImportParams) (*Dictionary, *ImportReport, error) {
+	return importDelimited(r, params, '\t')
+}
+
+// Import a dictionary from a CSV file. See importDelimited.
+func ImportFromCSV(params *ImportParams) (*Dictionary, *ImportReport, error) {
+	return importFile(params, ImportFromCSVReader)
+}
+
+// Import a dictionary from a TSV file. See importDelimited.
+func ImportFromTSV(params *ImportParams) (*Dictionary, *ImportReport, error) {
+	return importFile(params, ImportFromTSVReader)
+}
@@ -0,0 +1,325 @@
+package llex
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Convert a LIFT relation type to an llex one. FieldWorks capitalises and
+// pluralises its relation types, so the comparison is lenient.
+func liftImportRelationType(liftType string) string {
+	switch strings.TrimSuffix(strings.ToLower(liftType), "s") {
+	case "synonym":
+		return RelationSynonym
+	case "antonym":
+		return RelationAntonym
+	case "compare", "cf", "confer", "see-also":
+		return RelationSeeAlso
+	}
+	return liftType
+}
+
+// A LIFT entry, and the line it starts on.
+type liftEntryAt struct {
+	entry *liftEntry
+	line  int
+}
+
+// Read the entries of a LIFT document along with their line numbers.
+func readLiftEntries(r io.Reader) ([]*liftEntryAt, error) {
+	var entries []*liftEntryAt
+	decoder := xml.NewDecoder(r)
+
+	for {
+		line, _ := decoder.InputPos()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local == "lift" {
+			continue
+		}
+
+		if start.Name.Local != "entry" {
+			if err := decoder.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		entry := &liftEntry{}
+		if err := decoder.DecodeElement(entry, &start); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &liftEntryAt{entry: entry, line: line})
+	}
+}
+
+// Converts LIFT entries to llex entries.
+type liftImporter struct {
+	params  *ImportParams
+	report  *ImportReport
+	targets map[string]headwordKey // Headword and homograph number of each LIFT entry, by ID.
+}
+
+func (im *liftImporter) skip(line int, element string, reason string) error {
+	return im.report.skip(im.params, &ImportError{
+		File:   im.params.Filename,
+		Line:   line,
+		Marker: "<" + element + ">",
+		Reason: reason,
+	})
+}
+
+// Add a relation to an entry. References to entries that are not in the file are
+// kept as they are, since ExportLIFT writes the headword when there is no entry to
+// refer to.
+func (im *liftImporter) relation(entry *Entry, relation *liftRelation) {
+	imported := &Relation{Type: liftImportRelationType(relation.Type), Target: relation.Ref}
+	if target, ok := im.targets[relation.Ref]; ok {
+		imported.Target = target.word
+		imported.Homograph = target.homograph
+	}
+	entry.Relations = append(entry.Relations, imported)
+}
+
+func (im *liftImporter) sense(line int, entry *Entry, lift *liftSense, number string) error {
+	if lift.GrammaticalInfo != nil {
+		pos := lift.GrammaticalInfo.Value
+		if entry.POS == "" {
+			entry.POS = pos
+		} else if pos != entry.POS {
+			if err := im.skip(line, "grammatical-info", "sense has a different part of speech than the entry"); err != nil {
+				return err
+			}
+		}
+	}
+
+	sense := &Sense{Number: number}
+
+	if lift.Definition != nil {
+		for _, def := range strings.Split(lift.Definition.text(), ";") {
+			sense.Definitions = append(sense.Definitions, &Definition{Text: strings.TrimSpace(def)})
+		}
+	}
+
+	for _, gloss := range lift.Glosses {
+		sense.Glosses = append(sense.Glosses, gloss.Text)
+	}
+
+	// LIFT does not say which definition a reversal belongs to.
+	for _, reversal := range lift.Reversals {
+		if len(sense.Definitions) == 0 {
+			if err := im.skip(line, "reversal", "reversal of a sense without a definition"); err != nil {
+				return err
+			}
+			continue
+		}
+		def := sense.Definitions[0]
+		def.ReverseGlosses = append(def.ReverseGlosses, reversal.text())
+	}
+
+	for _, example := range lift.Examples {
+		imported := &Example{Text: example.text()}
+		if len(example.Translations) > 0 {
+			imported.Translation = example.Translations[0].text()
+		}
+		sense.Examples = append(sense.Examples, imported)
+	}
+
+	for _, note := range lift.Notes {
+		sense.Notes = append(sense.Notes, note.text())
+	}
+
+	for _, trait := range lift.Traits {
+		switch {
+		case strings.HasPrefix(trait.Name, "semantic-domain"):
+			sense.SemanticDomain = trait.Value
+		case trait.Name == liftUsageTypeTrait:
+			sense.Qualifiers = append(sense.Qualifiers, trait.Value)
+		case strings.HasPrefix(trait.Name, liftDefinitionQualifierTrait):
+			n, err := strconv.Atoi(strings.TrimPrefix(trait.Name, liftDefinitionQualifierTrait))
+			if err != nil || n < 1 || n > len(sense.Definitions) {
+				if err := im.skip(line, "trait", "qualifier for a missing definition '"+trait.Name+"'"); err != nil {
+					return err
+				}
+				continue
+			}
+			def := sense.Definitions[n-1]
+			def.Qualifiers = append(def.Qualifiers, trait.Value)
+		default:
+			if err := im.skip(line, "trait", "unknown sense trait '"+trait.Name+"'"); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, relation := range lift.Relations {
+		im.relation(entry, relation)
+	}
+
+	entry.Senses = append(entry.Senses, sense)
+	return nil
+}
+
+func (im *liftImporter) entry(at *liftEntryAt) (*Entry, error) {
+	lift := at.entry
+	entry := &Entry{
+		ID:        lift.ID,
+		Word:      lift.LexicalUnit.text(),
+		Homograph: lift.Order,
+		Senses:    make([]*Sense, 0),
+		Date:      lift.DateModified,
+	}
+
+	for _, pronunciation := range lift.Pronunciations {
+		ipa := &IPA{Text: pronunciation.text()}
+		for _, trait := range pronunciation.Traits {
+			if trait.Name != liftUsageTypeTrait {
+				if err := im.skip(at.line, "trait", "unknown pronunciation trait '"+trait.Name+"'"); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			ipa.Qualifiers = append(ipa.Qualifiers, trait.Value)
+		}
+		entry.Pronunciations = append(entry.Pronunciations, ipa)
+	}
+
+	for _, variant := range lift.Variants {
+		entry.Variants = append(entry.Variants, variant.text())
+	}
+
+	for i, sense := range lift.Senses {
+		// Only number senses if there is more than one of them.
+		number := ""
+		if len(lift.Senses) > 1 {
+			number = strconv.Itoa(i + 1)
+		}
+		if err := im.sense(at.line, entry, sense, number); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, note := range lift.Notes {
+		entry.UsageNotes = append(entry.UsageNotes, note.text())
+	}
+
+	for _, relation := range lift.Relations {
+		// Links to the main entry are handled by ImportFromLIFT.
+		if relation.Type == liftComponentRelation {
+			continue
+		}
+		im.relation(entry, relation)
+	}
+
+	for _, etymology := range lift.Etymologies {
+		text := etymology.text()
+		if etymology.Source != "" {
+			text = etymology.Source + " " + text
+		}
+		if etymology.Type == "borrowed" {
+			entry.BorrowedWord = text
+		} else {
+			entry.Etymology = text
+		}
+	}
+
+	for _, field := range lift.Fields {
+		if field.Type != "literal-meaning" {
+			if err := im.skip(at.line, "field", "unknown field type '"+field.Type+"'"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		entry.LiteralMeaning = field.text()
+	}
+
+	return entry, nil
+}
+
+// Import a dictionary from a LIFT file, as exported by FieldWorks (FLEx) and WeSay.
+//
+// Entries keep their LIFT IDs, and homograph numbers are read from the order
+// attribute. Each sense's definition is split into separate definitions at
+// semicolons, and senses are numbered when an entry has more than one of them.
+// Usage-type traits of senses and pronunciations become their qualifiers, as do
+// the definition-qualifier traits ExportLIFT writes for definitions. Reversals of a
+// sense become reverse glosses of its first definition. Entries linked to another entry as a component (complex forms, in FieldWorks
+// terms) become subentries of that entry.
+func ImportFromLIFT(params *ImportParams) (*Dictionary, *ImportReport, error) {
+	return importFile(params, ImportFromLIFTReader)
+}
+
+// Like ImportFromLIFT, but read the file from r. params.Filename is only used in
+// error messages.
+func ImportFromLIFTReader(r io.Reader, params *ImportParams) (*Dictionary, *ImportReport, error) {
+	liftEntries, err := readLiftEntries(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	im := &liftImporter{
+		params:  params,
+		report:  &ImportReport{},
+		targets: make(map[string]headwordKey),
+	}
+
+	for _, at := range liftEntries {
+		im.targets[at.entry.ID] = headwordKey{at.entry.LexicalUnit.text(), at.entry.Order}
+	}
+
+	dictionary := &Dictionary{Entries: make([]*Entry, 0)}
+	entriesByID := make(map[string]*Entry)
+	var subentries []*liftEntryAt
+
+	for _, at := range liftEntries {
+		entry, err := im.entry(at)
+		if err != nil {
+			return nil, nil, err
+		}
+		entriesByID[at.entry.ID] = entry
+
+		isSubentry := false
+		for _, relation := range at.entry.Relations {
+			if relation.Type == liftComponentRelation {
+				isSubentry = true
+			}
+		}
+
+		if isSubentry {
+			subentries = append(subentries, at)
+		} else {
+			dictionary.Entries = append(dictionary.Entries, entry)
+		}
+	}
+
+	for _, at := range subentries {
+		var parent *Entry
+		for _, relation := range at.entry.Relations {
+			if relation.Type == liftComponentRelation && entriesByID[relation.Ref] != nil {
+				parent = entriesByID[relation.Ref]
+				break
+			}
+		}
+
+		entry := entriesByID[at.entry.ID]
+		if parent == nil {
+			dictionary.Entries = append(dictionary.Entries, entry)
+			continue
+		}
+		parent.Subentries = append(parent.Subentries, entry)
+	}
+
+	if err := finishImport(dictionary, params); err != nil {
+		return nil, nil, err
+	}
+	return dictionary, im.report, nil
+}
@@ -1,66 +1,276 @@
 package llex
 
 import (
-	"bufio"
-	"os"
+	"io"
+	"strconv"
 	"strings"
 )
 
+// Create an entry for a \lx or \se record.
+func newLexiqueProEntry(word string) *Entry {
+	return &Entry{
+		Word:           word,
+		Senses:         make([]*Sense, 0),
+		Pronunciations: make([]*IPA, 0),
+		UsageNotes:     make([]string, 0),
+	}
+}
+
+// Markers understood by ImportFromLexiquePro.
+var lexiqueProMarkers = map[string]struct{}{
+	`\lx`: {}, `\se`: {}, `\sn`: {}, `\ps`: {}, `\de`: {}, `\ge`: {},
+	`\xv`: {}, `\xe`: {}, `\ph`: {}, `\nt`: {}, `\va`: {}, `\cf`: {},
+	`\sy`: {}, `\an`: {}, `\et`: {}, `\bw`: {}, `\lt`: {}, `\dt`: {},
+	`\sd`: {}, `\hm`: {}, `\mn`: {}, `\lf`: {}, `\re`: {},
+	// Qualifiers, which MDF has no markers for. These are written by ExportSFM
+	// after the sense (\zsq), definition (\zdq) or pronunciation (\zpq) they
+	// qualify, using the \z prefix Toolbox users give their own markers.
+	`\zsq`: {}, `\zdq`: {}, `\zpq`: {},
+}
+
+// Relation types for the MDF cross-reference markers.
+var lexiqueProRelations = map[string]string{
+	`\cf`: RelationSeeAlso,
+	`\sy`: RelationSynonym,
+	`\an`: RelationAntonym,
+	`\mn`: RelationVariantOf,
+}
+
+// Relation types for the labels of MDF lexical functions (\lf), which are used for
+// relations that have no marker of their own. Other labels are used as the type
+// as they are, in lower case.
+var lexicalFunctionRelations = map[string]string{
+	"syn": RelationSynonym,
+	"ant": RelationAntonym,
+	"cf":  RelationSeeAlso,
+}
+
+// Parse the value of a \lf field, such as "Syn = kana", into a relation.
+func parseLexicalFunction(value string) (*Relation, bool) {
+	label, target, ok := strings.Cut(value, "=")
+	label = strings.ToLower(strings.TrimSpace(label))
+	if !ok || label == "" {
+		return nil, false
+	}
+
+	relationType, known := lexicalFunctionRelations[label]
+	if !known {
+		relationType = strings.Join(strings.Fields(label), "-")
+	}
+	return &Relation{Type: relationType, Target: strings.TrimSpace(target)}, true
+}
+
+// Split a field holding several values separated by semicolons, such as
+// "a cow; an ox", leaving out blank values.
+func splitSFMList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
 // Import definitions from a Lexique Pro file.
-func ImportFromLexiquePro(filename string) (*Dictionary, error) {
-	file, err := os.Open(filename)
+//
+// Fields following a subentry (\se) belong to that subentry until the next \lx or
+// \se. Each sense number (\sn) starts a new sense, which definitions, glosses,
+// examples, notes and semantic domains belong to until the next \sn. Definitions
+// and glosses before the first \sn start an unnumbered sense, and notes before
+// any sense are usage notes for the whole entry.
+//
+// Homograph numbers are read from \hm, and cross-references to homographs are
+// written with the homograph number after the headword, as in "\cf kana2".
+// Relations other than those with their own markers (\cf, \sy, \an and \mn) are
+// read from lexical functions, as in "\lf derived-from = kana".
+//
+// Reversals (\re) are the reverse glosses of the definition before them, and
+// are separated by semicolons like definitions and glosses.
+//
+// Fields that cannot be imported, such as unknown markers or fields before the
+// first \lx, are listed in the returned report. If params.Strict is set, the first
+// of them is returned as an *ImportError instead.
+func ImportFromLexiquePro(params *ImportParams) (*Dictionary, *ImportReport, error) {
+	return importFile(params, ImportFromLexiqueProReader)
+}
+
+// Like ImportFromLexiquePro, but read the file from r. params.Filename is only
+// used in error messages.
+func ImportFromLexiqueProReader(r io.Reader, params *ImportParams) (*Dictionary, *ImportReport, error) {
+	fields, err := readSFMFields(r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer file.Close()
 
-	dictionary := &Dictionary{}
-
-	scanner := bufio.NewScanner(file)
+	dictionary := &Dictionary{Entries: make([]*Entry, 0)}
+	report := &ImportReport{}
 
 	var currentEntry *Entry
+	// The entry fields are being added to, which is either currentEntry or one
+	// of its subentries.
+	var target *Entry
+	// The sense of target that fields are being added to, if any.
+	var sense *Sense
 
-	for scanner.Scan() {
-		text := scanner.Text()
-		tokens := strings.Split(text, " ")
+	currentSense := func() *Sense {
+		if sense == nil {
+			sense = &Sense{}
+			target.Senses = append(target.Senses, sense)
+		}
+		return sense
+	}
+
+	for _, field := range fields {
+		value := field.Value
+
+		importErr := &ImportError{File: params.Filename, Line: field.Line, Marker: field.Marker}
+
+		if _, known := lexiqueProMarkers[field.Marker]; !known {
+			importErr.Reason = "unknown marker"
+			if err := report.skip(params, importErr); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if currentEntry == nil && field.Marker != `\lx` {
+			importErr.Reason = "field appears before the first \\lx"
+			if err := report.skip(params, importErr); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
 
-		switch tokens[0] {
+		switch field.Marker {
 		case `\lx`:
 			if currentEntry != nil {
 				dictionary.Entries = append(dictionary.Entries, currentEntry)
 			}
 
-			currentEntry = &Entry{Word: strings.Join(tokens[1:], " ")}
-			currentEntry.Definitions = make([]*Definition, 0)
-			currentEntry.Pronunciations = make([]*IPA, 0)
-			currentEntry.UsageNotes = make([]string, 0)
+			currentEntry = newLexiqueProEntry(value)
+			target = currentEntry
+			sense = nil
+		case `\se`:
+			subentry := newLexiqueProEntry(value)
+			currentEntry.Subentries = append(currentEntry.Subentries, subentry)
+			target = subentry
+			sense = nil
+		case `\sn`:
+			sense = &Sense{Number: value}
+			target.Senses = append(target.Senses, sense)
+		case `\hm`:
+			homograph, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || homograph < 0 {
+				importErr.Reason = "homograph number must be a whole number"
+				if err := report.skip(params, importErr); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			target.Homograph = homograph
 		case `\ps`:
-			currentEntry.POS = strings.Join(tokens[1:], " ")
-		case `\de`, `\ge`:
-			definitions := strings.Split(strings.Join(tokens[1:], " "), ";")
-			for _, def := range definitions {
-				currentEntry.Definitions = append(currentEntry.Definitions, &Definition{
-					Text: def,
-				})
+			target.POS = value
+		case `\de`:
+			s := currentSense()
+			for _, def := range splitSFMList(value) {
+				s.Definitions = append(s.Definitions, &Definition{Text: def})
+			}
+		case `\ge`:
+			s := currentSense()
+			s.Glosses = append(s.Glosses, splitSFMList(value)...)
+		case `\xv`:
+			s := currentSense()
+			s.Examples = append(s.Examples, &Example{Text: value})
+		case `\xe`:
+			// A translation belongs to the preceding example, unless that example
+			// already has one.
+			s := currentSense()
+			n := len(s.Examples)
+			if n == 0 || s.Examples[n-1].Translation != "" {
+				s.Examples = append(s.Examples, &Example{})
+				n++
+			}
+			s.Examples[n-1].Translation = value
+		case `\sd`:
+			currentSense().SemanticDomain = value
+		case `\re`:
+			if sense == nil || len(sense.Definitions) == 0 {
+				importErr.Reason = "reversal must follow a \\de field"
+				if err := report.skip(params, importErr); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			def := sense.Definitions[len(sense.Definitions)-1]
+			def.ReverseGlosses = append(def.ReverseGlosses, splitSFMList(value)...)
+		case `\zsq`:
+			s := currentSense()
+			s.Qualifiers = append(s.Qualifiers, value)
+		case `\zdq`:
+			if sense == nil || len(sense.Definitions) == 0 {
+				importErr.Reason = "definition qualifier must follow a \\de field"
+				if err := report.skip(params, importErr); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			def := sense.Definitions[len(sense.Definitions)-1]
+			def.Qualifiers = append(def.Qualifiers, value)
+		case `\zpq`:
+			if len(target.Pronunciations) == 0 {
+				importErr.Reason = "pronunciation qualifier must follow a \\ph field"
+				if err := report.skip(params, importErr); err != nil {
+					return nil, nil, err
+				}
+				continue
 			}
+			ipa := target.Pronunciations[len(target.Pronunciations)-1]
+			ipa.Qualifiers = append(ipa.Qualifiers, value)
+		case `\ph`:
+			target.Pronunciations = append(target.Pronunciations, &IPA{Text: value})
+		case `\nt`:
+			if sense != nil {
+				sense.Notes = append(sense.Notes, value)
+			} else {
+				target.UsageNotes = append(target.UsageNotes, value)
+			}
+		case `\va`:
+			target.Variants = append(target.Variants, value)
+		case `\cf`, `\sy`, `\an`, `\mn`:
+			target.Relations = append(target.Relations, &Relation{
+				Type:   lexiqueProRelations[field.Marker],
+				Target: value,
+			})
+		case `\lf`:
+			relation, ok := parseLexicalFunction(value)
+			if !ok {
+				importErr.Reason = "lexical function must be written as 'label = headword'"
+				if err := report.skip(params, importErr); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			target.Relations = append(target.Relations, relation)
 		case `\et`:
-			currentEntry.Etymology = strings.Join(tokens[1:], " ")
+			target.Etymology = value
 		case `\bw`:
-			currentEntry.BorrowedWord = strings.Join(tokens[1:], " ")
+			target.BorrowedWord = value
 		case `\lt`:
-			currentEntry.LiteralMeaning = strings.Join(tokens[1:], " ")
-		default:
-			continue
+			target.LiteralMeaning = value
+		case `\dt`:
+			target.Date = value
 		}
-
 	}
 
 	// Add the final entry to the list.
-	dictionary.Entries = append(dictionary.Entries, currentEntry)
+	if currentEntry != nil {
+		dictionary.Entries = append(dictionary.Entries, currentEntry)
+	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if err := finishImport(dictionary, params); err != nil {
+		return nil, nil, err
 	}
 
-	return dictionary, nil
+	return dictionary, report, nil
 }
@@ -0,0 +1,138 @@
+package llex
+
+import "encoding/xml"
+
+// The subset of the Lexicon Interchange FormaT (LIFT) used by FieldWorks, WeSay and
+// similar tools that llex understands. See https://github.com/sillsdev/lift-standard.
+
+const liftVersion = "0.13"
+
+// Language codes used when exporting. Conlangs have no ISO 639 code, so the
+// vernacular forms use "qaa", the first code reserved for local use.
+const (
+	liftVernacularLang = "qaa"
+	liftAnalysisLang   = "en"
+)
+
+// Relation type FieldWorks uses to link a complex form, such as a subentry, to
+// the entry it is formed from.
+const liftComponentRelation = "_component-lexeme"
+
+// Names of the sense traits FieldWorks uses for semantic domains and for labels
+// such as "archaic", which llex keeps as the sense's qualifiers.
+const (
+	liftSemanticDomainTrait = "semantic-domain-ddp4"
+	liftUsageTypeTrait      = "usage-type"
+)
+
+// Prefix of the names of the sense traits llex writes for the qualifiers of
+// definitions, which LIFT has no place for. The definition's position in the sense
+// follows, as in "definition-qualifier-2".
+const liftDefinitionQualifierTrait = "definition-qualifier-"
+
+type liftForm struct {
+	Lang string `xml:"lang,attr"`
+	Text string `xml:"text"`
+}
+
+// A piece of text given in one or more languages.
+type liftMultiText struct {
+	Forms []liftForm `xml:"form"`
+}
+
+// The text of the first form, which is the one llex uses.
+func (m *liftMultiText) text() string {
+	if m == nil || len(m.Forms) == 0 {
+		return ""
+	}
+	return m.Forms[0].Text
+}
+
+func newLiftMultiText(lang string, text string) *liftMultiText {
+	return &liftMultiText{Forms: []liftForm{{Lang: lang, Text: text}}}
+}
+
+type liftTrait struct {
+	Name  string `xml:"name,attr,omitempty"`
+	Value string `xml:"value,attr"`
+}
+
+type liftRelation struct {
+	Type string `xml:"type,attr"`
+	Ref  string `xml:"ref,attr"`
+}
+
+type liftNote struct {
+	Type string `xml:"type,attr,omitempty"`
+	liftMultiText
+}
+
+type liftField struct {
+	Type string `xml:"type,attr"`
+	liftMultiText
+}
+
+type liftEtymology struct {
+	Type   string `xml:"type,attr"`
+	Source string `xml:"source,attr,omitempty"`
+	liftMultiText
+}
+
+// A pronunciation, with traits for its qualifiers.
+type liftIPA struct {
+	liftMultiText
+	Traits []*liftTrait `xml:"trait"`
+}
+
+// A form to list a sense under in a reversal index, such as an English-to-conlang
+// index.
+type liftReversal struct {
+	Type string `xml:"type,attr,omitempty"` // The language of the index.
+	liftMultiText
+}
+
+type liftExample struct {
+	liftMultiText
+	Translations []*liftMultiText `xml:"translation"`
+}
+
+type liftSense struct {
+	ID              string          `xml:"id,attr,omitempty"`
+	GrammaticalInfo *liftTrait      `xml:"grammatical-info"`
+	Glosses         []liftForm      `xml:"gloss"`
+	Definition      *liftMultiText  `xml:"definition"`
+	Examples        []*liftExample  `xml:"example"`
+	Notes           []*liftNote     `xml:"note"`
+	Relations       []*liftRelation `xml:"relation"`
+	Reversals       []*liftReversal `xml:"reversal"`
+	Traits          []*liftTrait    `xml:"trait"`
+}
+
+type liftEntry struct {
+	ID             string           `xml:"id,attr,omitempty"`
+	Order          int              `xml:"order,attr,omitempty"` // Homograph number.
+	DateModified   string           `xml:"dateModified,attr,omitempty"`
+	LexicalUnit    *liftMultiText   `xml:"lexical-unit"`
+	Pronunciations []*liftIPA       `xml:"pronunciation"`
+	Variants       []*liftMultiText `xml:"variant"`
+	Senses         []*liftSense     `xml:"sense"`
+	Notes          []*liftNote      `xml:"note"`
+	Relations      []*liftRelation  `xml:"relation"`
+	Etymologies    []*liftEtymology `xml:"etymology"`
+	Fields         []*liftField     `xml:"field"`
+}
+
+type liftDocument struct {
+	XMLName  xml.Name     `xml:"lift"`
+	Version  string       `xml:"version,attr"`
+	Producer string       `xml:"producer,attr,omitempty"`
+	Entries  []*liftEntry `xml:"entry"`
+}
+
+// LIFT relation types for each llex relation type. Relations of other types are
+// exported with their type unchanged.
+var liftRelationTypes = map[string]string{
+	RelationSynonym: "synonym",
+	RelationAntonym: "antonym",
+	RelationSeeAlso: "compare",
+}
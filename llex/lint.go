@@ -0,0 +1,364 @@
+package llex
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// How serious a lint issue is.
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"   // The lexicon is broken, and exports will be wrong.
+	LintWarning LintSeverity = "warning" // The lexicon is probably not what was intended.
+)
+
+// A problem found in a lexicon by a lint rule.
+type LintIssue struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Path     string       `json:"path"`           // Where the problem is, such as entries[3].definitions[0].
+	Word     string       `json:"word,omitempty"` // Headword of the entry with the problem.
+	Message  string       `json:"message"`
+	Fixable  bool         `json:"fixable"` // Whether FixLint can fix the problem.
+}
+
+// A check for one kind of problem in a lexicon.
+type LintRule struct {
+	Name        string
+	Description string
+	Severity    LintSeverity
+
+	check func(dict *Dictionary, report func(path string, word string, message string))
+	fix   func(dict *Dictionary) int // Returns the number of problems fixed. Nil if the rule cannot fix anything.
+}
+
+// Whether FixLint can fix the problems found by the rule.
+func (r *LintRule) Fixable() bool {
+	return r.fix != nil
+}
+
+// Call fn for each entry and subentry, along with its path.
+func walkEntries(entries []*Entry, path string, fn func(entry *Entry, path string)) {
+	for i, entry := range entries {
+		entryPath := fmt.Sprintf("%s[%d]", path, i)
+		fn(entry, entryPath)
+		walkEntries(entry.Subentries, entryPath+".subentries", fn)
+	}
+}
+
+var entriesType = reflect.TypeOf([]*Entry(nil))
+
+// Call fn for each string in v, along with its path. Subentries are not visited, as
+// they are visited separately by walkEntries.
+func walkStrings(v reflect.Value, path string, fn func(path string, s reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			walkStrings(v.Elem(), path, fn)
+		}
+	case reflect.String:
+		fn(path, v)
+	case reflect.Slice:
+		if v.Type() == entriesType {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if name := jsonFieldName(v.Type().Field(i)); name != "" {
+				walkStrings(v.Field(i), path+"."+name, fn)
+			}
+		}
+	}
+}
+
+// Call fn for each string in the dictionary that has leading or trailing
+// whitespace.
+func walkUntrimmedStrings(dict *Dictionary, fn func(path string, word string, s reflect.Value)) {
+	if dict.LanguageName != strings.TrimSpace(dict.LanguageName) {
+		fn("languageName", "", reflect.ValueOf(&dict.LanguageName).Elem())
+	}
+	walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+		walkStrings(reflect.ValueOf(entry), path, func(path string, s reflect.Value) {
+			if s.String() != strings.TrimSpace(s.String()) {
+				fn(path, entry.Word, s)
+			}
+		})
+	})
+}
+
+var lintRules = []*LintRule{
+	{
+		Name:        "empty-word",
+		Description: "Entries without a headword, which are left out of websites",
+		Severity:    LintError,
+		check: func(dict *Dictionary, report func(string, string, string)) {
+			walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+				if strings.TrimSpace(entry.Word) == "" {
+					report(path+".word", "", "entry has no headword")
+				}
+			})
+		},
+	},
+	{
+		Name:        "duplicate-headword",
+		Description: "Entries with the same headword, part of speech and senses as an earlier entry",
+		Severity:    LintError,
+		check: func(dict *Dictionary, report func(string, string, string)) {
+			// Homograph numbers are not compared, since reading a lexicon numbers
+			// every entry that shares its headword, including duplicates. Homographs
+			// with the same part of speech are told apart by their senses instead.
+			type duplicateKey struct {
+				word   string
+				pos    string
+				senses string
+			}
+			seen := make(map[duplicateKey]int)
+			for i, entry := range dict.Entries {
+				senses, err := json.Marshal(entry.Senses)
+				if err != nil {
+					continue
+				}
+				key := duplicateKey{dict.foldWord(entry.Word), entry.POS, string(senses)}
+				if first, ok := seen[key]; ok {
+					report(fmt.Sprintf("entries[%d]", i), entry.Word, fmt.Sprintf("same headword, part of speech and senses as entries[%d]", first))
+					continue
+				}
+				seen[key] = i
+			}
+		},
+	},
+	{
+		Name:        "no-definitions",
+		Description: "Entries without any definitions or glosses",
+		Severity:    LintWarning,
+		check: func(dict *Dictionary, report func(string, string, string)) {
+			walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+				for _, sense := range entry.Senses {
+					if len(sense.Definitions) > 0 || len(sense.Glosses) > 0 {
+						return
+					}
+				}
+				report(path+".senses", entry.Word, "entry has no definitions or glosses")
+			})
+		},
+	},
+	{
+		Name:        "blank-definition",
+		Description: "Definitions and glosses without any text, such as those left by a trailing semicolon",
+		Severity:    LintWarning,
+		check: func(dict *Dictionary, report func(string, string, string)) {
+			walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+				for i, sense := range entry.Senses {
+					sensePath := fmt.Sprintf("%s.senses[%d]", path, i)
+					for j, def := range sense.Definitions {
+						if strings.TrimSpace(def.Text) == "" {
+							report(fmt.Sprintf("%s.definitions[%d]", sensePath, j), entry.Word, "definition is blank")
+						}
+					}
+					for j, gloss := range sense.Glosses {
+						if strings.TrimSpace(gloss) == "" {
+							report(fmt.Sprintf("%s.glosses[%d]", sensePath, j), entry.Word, "gloss is blank")
+						}
+					}
+				}
+			})
+		},
+		fix: func(dict *Dictionary) int {
+			fixed := 0
+			walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+				for _, sense := range entry.Senses {
+					definitions := sense.Definitions[:0]
+					for _, def := range sense.Definitions {
+						if strings.TrimSpace(def.Text) == "" {
+							fixed++
+							continue
+						}
+						definitions = append(definitions, def)
+					}
+					sense.Definitions = definitions
+
+					glosses := sense.Glosses[:0]
+					for _, gloss := range sense.Glosses {
+						if strings.TrimSpace(gloss) == "" {
+							fixed++
+							continue
+						}
+						glosses = append(glosses, gloss)
+					}
+					sense.Glosses = glosses
+				}
+			})
+			return fixed
+		},
+	},
+	{
+		Name:        "dangling-relation",
+		Description: "Relations to entries that are not in the lexicon, or to a headword with several homographs",
+		Severity:    LintWarning,
+		check: func(dict *Dictionary, report func(string, string, string)) {
+			for _, dangling := range resolveRelations(dict, false) {
+				report(dangling.Path, dangling.Entry.Word, dangling.Reason)
+			}
+		},
+	},
+	{
+		Name:        "unknown-letter",
+		Description: "Headwords with letters that are not in the lexicon's alphabet, if it has one",
+		Severity:    LintWarning,
+		check: func(dict *Dictionary, report func(string, string, string)) {
+			if dict.Alphabet == nil || len(dict.Alphabet.Letters) == 0 {
+				return
+			}
+			collator := dict.Collator()
+			walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+				for _, letter := range collator.Letters(entry.Word) {
+					if !collator.inAlphabet(letter) {
+						report(path+".word", entry.Word, fmt.Sprintf("'%s' is not in the alphabet", letter))
+					}
+				}
+			})
+		},
+	},
+	{
+		Name:        "whitespace",
+		Description: "Text with leading or trailing whitespace",
+		Severity:    LintWarning,
+		check: func(dict *Dictionary, report func(string, string, string)) {
+			walkUntrimmedStrings(dict, func(path string, word string, s reflect.Value) {
+				report(path, word, fmt.Sprintf("%q has leading or trailing whitespace", s.String()))
+			})
+		},
+		fix: func(dict *Dictionary) int {
+			fixed := 0
+			walkUntrimmedStrings(dict, func(path string, word string, s reflect.Value) {
+				s.SetString(strings.TrimSpace(s.String()))
+				fixed++
+			})
+			return fixed
+		},
+	},
+}
+
+// All lint rules, in the order they are run.
+func LintRules() []*LintRule {
+	return append([]*LintRule(nil), lintRules...)
+}
+
+// Find a lint rule by name.
+func LookupLintRule(name string) (*LintRule, bool) {
+	for _, rule := range lintRules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// Parameters for Lint and FixLint.
+type LintParams struct {
+	Enable  []string // Names of the rules to run. All rules are run if empty.
+	Disable []string // Names of rules not to run.
+}
+
+// Returned when LintParams names a rule that does not exist.
+type UnknownLintRuleError struct {
+	Name string
+}
+
+func (e *UnknownLintRuleError) Error() string {
+	return fmt.Sprintf("unknown lint rule '%s'", e.Name)
+}
+
+// The rules selected by the parameters, in the order they are run.
+func (params *LintParams) Rules() ([]*LintRule, error) {
+	for _, name := range append(append([]string(nil), params.Enable...), params.Disable...) {
+		if _, ok := LookupLintRule(name); !ok {
+			return nil, &UnknownLintRuleError{Name: name}
+		}
+	}
+
+	contains := func(names []string, name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var rules []*LintRule
+	for _, rule := range lintRules {
+		if len(params.Enable) > 0 && !contains(params.Enable, rule.Name) {
+			continue
+		}
+		if contains(params.Disable, rule.Name) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// The problems found in a lexicon by Lint.
+type LintReport struct {
+	Issues []*LintIssue `json:"issues"`
+}
+
+// Number of issues with the given severity.
+func (r *LintReport) Count(severity LintSeverity) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Check a lexicon for problems such as missing headwords and duplicate entries.
+func Lint(dict *Dictionary, params *LintParams) (*LintReport, error) {
+	rules, err := params.Rules()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LintReport{Issues: []*LintIssue{}}
+	for _, rule := range rules {
+		rule.check(dict, func(path string, word string, message string) {
+			report.Issues = append(report.Issues, &LintIssue{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Path:     path,
+				Word:     word,
+				Message:  message,
+				Fixable:  rule.Fixable(),
+			})
+		})
+	}
+
+	return report, nil
+}
+
+// Fix the problems that the selected rules can fix mechanically, such as stray
+// whitespace, modifying the dictionary in place. Returns the number of problems
+// fixed.
+func FixLint(dict *Dictionary, params *LintParams) (int, error) {
+	rules, err := params.Rules()
+	if err != nil {
+		return 0, err
+	}
+
+	fixed := 0
+	for _, rule := range rules {
+		if rule.fix != nil {
+			fixed += rule.fix(dict)
+		}
+	}
+	return fixed, nil
+}
@@ -0,0 +1,69 @@
+package llex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateHeadword(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []*Entry
+		want    int
+	}{
+		{"numbered homographs", []*Entry{
+			{Word: "bat", Homograph: 1, POS: "n", Senses: []*Sense{{Glosses: []string{"club"}}}},
+			{Word: "bat", Homograph: 2, POS: "n", Senses: []*Sense{{Glosses: []string{"flying mammal"}}}},
+		}, 0},
+		{"different parts of speech", []*Entry{
+			{Word: "bat", POS: "n", Senses: []*Sense{{Glosses: []string{"club"}}}},
+			{Word: "bat", POS: "v", Senses: []*Sense{{Glosses: []string{"club"}}}},
+		}, 0},
+		{"numbered duplicates", []*Entry{
+			{Word: "bat", Homograph: 1, POS: "n", Senses: []*Sense{{Glosses: []string{"club"}}}},
+			{Word: "Bat", Homograph: 2, POS: "n", Senses: []*Sense{{Glosses: []string{"club"}}}},
+		}, 1},
+		{"unnumbered duplicates", []*Entry{
+			{Word: "bat", POS: "n"},
+			{Word: "bat", POS: "n"},
+		}, 1},
+	}
+
+	for _, test := range tests {
+		dict := &Dictionary{Entries: test.entries}
+		report, err := Lint(dict, &LintParams{Enable: []string{"duplicate-headword"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Issues) != test.want {
+			t.Errorf("%s: got %d issues, want %d: %+v", test.name, len(report.Issues), test.want, report.Issues)
+		}
+	}
+}
+
+// Reading a lexicon numbers duplicate entries as homographs, which must not hide
+// them from the rule.
+func TestDuplicateHeadwordAfterReading(t *testing.T) {
+	data := fmt.Sprintf(`{
+  "formatVersion": %d,
+  "languageName": "Test",
+  "entries": [
+    {"word": "bat", "partOfSpeech": "n", "senses": [{"glosses": ["club"]}]},
+    {"word": "bat", "partOfSpeech": "n", "senses": [{"glosses": ["club"]}]},
+    {"word": "bat", "partOfSpeech": "n", "senses": [{"glosses": ["flying mammal"]}]}
+  ]
+}`, CurrentFormatVersion)
+
+	dict, err := ReadDictionaryFrom(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Lint(dict, &LintParams{Enable: []string{"duplicate-headword"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Path != "entries[1]" {
+		t.Errorf("got %+v, want one issue for entries[1]", report.Issues)
+	}
+}
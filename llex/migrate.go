@@ -0,0 +1,228 @@
+package llex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// The version of the lexicon file format written by this version of llex. It must
+// be increased, and a migration added, whenever a change to the types in types.go
+// would stop older files from being read correctly.
+const CurrentFormatVersion = 3
+
+// An upgrade of a lexicon document from one format version to the next.
+//
+// Migrations work on the decoded JSON document rather than on a Dictionary, since
+// older documents may not fit the current types.
+type migration struct {
+	from        int // The version being upgraded from, to from+1.
+	description string
+	// Upgrade the document in place, returning a description of each change
+	// made, if there is more to say than the migration's description.
+	migrate func(doc map[string]any) ([]string, error)
+}
+
+var migrations = []migration{
+	{
+		from:        0,
+		description: "Add the formatVersion field",
+		migrate: func(doc map[string]any) ([]string, error) {
+			return nil, nil
+		},
+	},
+	{
+		from:        1,
+		description: "Group definitions and examples into senses",
+		migrate: func(doc map[string]any) ([]string, error) {
+			entries, _ := doc["entries"].([]any)
+			migrateToSenses(entries)
+			return nil, nil
+		},
+	},
+	{
+		from:        2,
+		description: "Give each entry an ID and homograph number",
+		migrate: func(doc map[string]any) ([]string, error) {
+			// Done by AssignEntryIDs, which runs whenever a dictionary is read so
+			// that entries added by hand get IDs too.
+			return nil, nil
+		},
+	},
+}
+
+// Move the definitions and examples of each entry into senses, one for each sense
+// number they were tagged with, in the order the sense numbers first appear.
+func migrateToSenses(entries []any) {
+	for _, raw := range entries {
+		entry, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		senses := []any{}
+		byNumber := make(map[string]map[string]any)
+
+		for _, key := range []string{"definitions", "examples"} {
+			items, _ := entry[key].([]any)
+			for _, rawItem := range items {
+				item, ok := rawItem.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				number, _ := item["sense"].(string)
+				delete(item, "sense")
+
+				sense, ok := byNumber[number]
+				if !ok {
+					sense = make(map[string]any)
+					if number != "" {
+						sense["number"] = number
+					}
+					byNumber[number] = sense
+					senses = append(senses, sense)
+				}
+
+				list, _ := sense[key].([]any)
+				sense[key] = append(list, item)
+			}
+			delete(entry, key)
+		}
+
+		entry["senses"] = senses
+
+		subentries, _ := entry["subentries"].([]any)
+		migrateToSenses(subentries)
+	}
+}
+
+// What was done to bring a document up to the current format version.
+type MigrationReport struct {
+	FromVersion int
+	ToVersion   int
+	Changes     []string // A description of each change made, in order.
+}
+
+// Whether the document needed upgrading at all.
+func (r *MigrationReport) Migrated() bool {
+	return r.FromVersion != r.ToVersion
+}
+
+// Returned when a document was written by a newer version of llex than this one.
+type UnsupportedVersionError struct {
+	Version int
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("lexicon format version %d is newer than this version of llex supports (%d); upgrade llex", e.Version, CurrentFormatVersion)
+}
+
+// Get the format version of a document. Documents from before versions were
+// introduced have no version, which counts as version 0.
+func documentVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["formatVersion"]
+	if !ok {
+		return 0, nil
+	}
+
+	number, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("formatVersion must be a number, not %v", raw)
+	}
+
+	version, err := number.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("formatVersion must be a whole number, not %v", number)
+	}
+
+	return int(version), nil
+}
+
+// Upgrade a decoded lexicon document to the current format version in place.
+func migrateDocument(doc map[string]any) (*MigrationReport, error) {
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentFormatVersion {
+		return nil, &UnsupportedVersionError{Version: version}
+	}
+
+	report := &MigrationReport{FromVersion: version, ToVersion: CurrentFormatVersion}
+
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+
+		changes, err := m.migrate(doc)
+		if err != nil {
+			return nil, fmt.Errorf("migrating from format version %d: %w", m.from, err)
+		}
+
+		report.Changes = append(report.Changes, fmt.Sprintf("%d → %d: %s", m.from, m.from+1, m.description))
+		for _, change := range changes {
+			report.Changes = append(report.Changes, "  "+change)
+		}
+	}
+
+	doc["formatVersion"] = CurrentFormatVersion
+	return report, nil
+}
+
+// Parameters for reading a dictionary.
+type ReadParams struct {
+	// Reject dictionaries with fields that llex does not know about, such as
+	// misspelt ones, with an *UnknownFieldsError. The check happens after the
+	// dictionary is upgraded to the current format version.
+	DisallowUnknownFields bool
+}
+
+// Read a dictionary from r, upgrading it to the current format version, and report
+// what had to be changed.
+func MigrateDictionaryFrom(r io.Reader) (*Dictionary, *MigrationReport, error) {
+	return ReadDictionaryWithParams(r, &ReadParams{})
+}
+
+// Like MigrateDictionaryFrom, with parameters controlling how the dictionary is read.
+func ReadDictionaryWithParams(r io.Reader, params *ReadParams) (*Dictionary, *MigrationReport, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+
+	report, err := migrateDocument(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if params.DisallowUnknownFields {
+		if err := checkUnknownFields(doc); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Round-trip the upgraded document through JSON to decode it into the
+	// current types.
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var dict Dictionary
+	if err := json.NewDecoder(bytes.NewReader(migrated)).Decode(&dict); err != nil {
+		return nil, nil, err
+	}
+	if _, err := NormalizeDictionary(&dict); err != nil {
+		return nil, nil, err
+	}
+	AssignEntryIDs(&dict)
+	ResolveRelations(&dict)
+
+	return &dict, report, nil
+}
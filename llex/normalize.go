@@ -0,0 +1,119 @@
+package llex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Unicode normalization forms that a lexicon's text can be kept in.
+const (
+	NormalizationNFC = "NFC" // Composed, so that "é" is a single character.
+	NormalizationNFD = "NFD" // Decomposed, so that "é" is "e" followed by a combining accent.
+)
+
+// Returned when a lexicon asks for a normalization form that llex does not support.
+type UnknownNormalizationError struct {
+	Form string
+}
+
+func (e *UnknownNormalizationError) Error() string {
+	return fmt.Sprintf("unknown normalization form '%s'; use %s or %s", e.Form, NormalizationNFC, NormalizationNFD)
+}
+
+// Look up a normalization form by name. An empty name means text is left as it is.
+func normalizationForm(name string) (form norm.Form, ok bool, err error) {
+	switch strings.ToUpper(name) {
+	case "":
+		return 0, false, nil
+	case NormalizationNFC:
+		return norm.NFC, true, nil
+	case NormalizationNFD:
+		return norm.NFD, true, nil
+	}
+	return 0, false, &UnknownNormalizationError{Form: name}
+}
+
+// Put every piece of text in the dictionary into its normalization form, returning
+// the number of strings that changed. Dictionaries without a normalization form are
+// left as they are.
+//
+// Dictionaries returned by ReadDictionary and by importers have already been
+// normalized.
+func NormalizeDictionary(dict *Dictionary) (int, error) {
+	form, ok, err := normalizationForm(dict.Normalization)
+	if err != nil || !ok {
+		return 0, err
+	}
+	dict.Normalization = strings.ToUpper(dict.Normalization)
+
+	changed := 0
+	normalize := func(path string, s reflect.Value) {
+		if normalized := form.String(s.String()); normalized != s.String() {
+			s.SetString(normalized)
+			changed++
+		}
+	}
+
+	walkStrings(reflect.ValueOf(&dict.LanguageName), "languageName", normalize)
+	walkStrings(reflect.ValueOf(dict.Alphabet), "alphabet", normalize)
+	walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+		walkStrings(reflect.ValueOf(entry), path, normalize)
+	})
+
+	return changed, nil
+}
+
+// Whether all the text in a lexicon file is in a normalization form. The file is
+// checked as it is, since ReadDictionary normalizes the text it reads. Files are
+// always normalized if form is empty.
+func IsNormalizedFile(data []byte, form string) (bool, error) {
+	f, ok, err := normalizationForm(form)
+	if err != nil || !ok {
+		return true, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return false, err
+	}
+
+	var normalized func(v any) bool
+	normalized = func(v any) bool {
+		switch v := v.(type) {
+		case string:
+			return f.IsNormalString(v)
+		case []any:
+			for _, item := range v {
+				if !normalized(item) {
+					return false
+				}
+			}
+		case map[string]any:
+			for key, value := range v {
+				if !f.IsNormalString(key) || !normalized(value) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return normalized(doc), nil
+}
+
+// The form of a headword used to tell whether two headwords are the same word.
+// Headwords are always compared in NFC, whatever form the dictionary keeps them in,
+// and case is ignored unless the dictionary is case-sensitive.
+func (d *Dictionary) foldWord(word string) string {
+	word = norm.NFC.String(word)
+	if !d.CaseSensitive {
+		word = strings.ToLower(word)
+	}
+	return word
+}
@@ -0,0 +1,33 @@
+package llex
+
+import "testing"
+
+func TestIsNormalizedFile(t *testing.T) {
+	const (
+		composed   = "café"
+		decomposed = "café"
+	)
+	tests := []struct {
+		data string
+		form string
+		want bool
+	}{
+		{`{"languageName": "` + composed + `"}`, NormalizationNFC, true},
+		{`{"languageName": "` + decomposed + `"}`, NormalizationNFC, false},
+		{`{"entries": [{"word": "` + decomposed + `"}]}`, NormalizationNFC, false},
+		{`{"entries": [{"word": "café"}]}`, NormalizationNFC, false},
+		{`{"entries": [{"word": "` + decomposed + `"}]}`, NormalizationNFD, true},
+		{`{"entries": [{"word": "` + composed + `"}]}`, NormalizationNFD, false},
+		{`{"entries": [{"word": "` + decomposed + `"}]}`, "", true},
+	}
+
+	for _, test := range tests {
+		got, err := IsNormalizedFile([]byte(test.data), test.form)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("IsNormalizedFile(%q, %q) = %v, want %v", test.data, test.form, got, test.want)
+		}
+	}
+}
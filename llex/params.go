@@ -12,17 +12,28 @@ type ExportParams struct {
 	AuthorsNote    string
 	OutputPath     string
 	UseEmbeddedCSS bool
-	CSS            template.CSS
+	CSS            template.CSS // The stylesheet for HTML exports. See ThemeCSS.
 	CSSFile        string
 	Multipage      bool
 	ShowNavbar     bool
 	IndexPage      bool
+	Search         bool // Whether HTML pages have a search box. See PageData.
 	HTMLEntries    []template.HTML
 	NavbarHTML     template.HTML
 	Timestamp      time.Time
 	GenerationTime time.Duration
 	NumWords       int
 	Author         string
+	GlossLanguage  string     // The language entries are defined in, such as English.
+	Templates      *Templates // Templates for HTML exports. The built-in ones are used if this is nil.
+	Columns        []string   // The fields to export, for tabular formats such as CSV.
+
+	// The reverse index, for HTML exports. See PageData.
+	ReverseEntries   []template.HTML
+	ReverseIndexHref string
+
+	// Options specific to the format being exported to, as listed in its FormatInfo.
+	Options map[string]string
 }
 
 // Create a default ExportParams object.
@@ -31,6 +42,7 @@ func NewExportParams(dict *Dictionary) *ExportParams {
 		Dictionary:     dict,
 		LanguageName:   dict.LanguageName,
 		Copyright:      "No copyright information provided.",
+		GlossLanguage:  "English",
 		UseEmbeddedCSS: true,
 		CSS:            template.CSS(CSS), // Default CSS
 		Timestamp:      time.Now(),
@@ -38,24 +50,48 @@ func NewExportParams(dict *Dictionary) *ExportParams {
 	}
 }
 
-// Converts an ExportParams object into a map[string]any for passing them to
-// HTML templates.
-func (p *ExportParams) ToTemplateParams() map[string]any {
-	return map[string]any{
-		"LanguageName":   p.LanguageName,
-		"HTMLEntries":    p.HTMLEntries,
-		"Timestamp":      p.Timestamp,
-		"NumWords":       p.NumWords,
-		"GenerationTime": p.GenerationTime,
-		"Copyright":      template.HTML(p.Copyright),
-		"AuthorsNote":    template.HTML(p.AuthorsNote),
-		"UseEmbeddedCSS": p.UseEmbeddedCSS,
-		"CSS":            p.CSS,
-		"CSSFile":        template.HTML(p.CSSFile),
-		"Multipage":      p.Multipage,
-		"ShowNavbar":     p.ShowNavbar,
-		"NavbarHTML":     p.NavbarHTML,
-		"IndexPage":      p.IndexPage,
-		"Author":         p.Author,
+// The templates to render HTML with.
+func (p *ExportParams) templates() *Templates {
+	if p.Templates == nil {
+		return DefaultTemplates()
+	}
+	return p.Templates
+}
+
+// The stylesheet for HTML exports: the templates' own stylesheet if they have one,
+// and p.CSS otherwise.
+func (p *ExportParams) stylesheet() template.CSS {
+	if css := p.templates().CSS; css != "" {
+		return template.CSS(css)
+	}
+	return p.CSS
+}
+
+// The data to execute the page template with.
+func (p *ExportParams) TemplateData() *PageData {
+	return &PageData{
+		LanguageName:   p.LanguageName,
+		GlossLanguage:  p.GlossLanguage,
+		Author:         p.Author,
+		Copyright:      template.HTML(p.Copyright),
+		AuthorsNote:    template.HTML(p.AuthorsNote),
+		HTMLEntries:    p.HTMLEntries,
+		NumWords:       p.NumWords,
+		Timestamp:      p.Timestamp,
+		GenerationTime: p.GenerationTime,
+		UseEmbeddedCSS: p.UseEmbeddedCSS,
+		CSS:            p.stylesheet(),
+		CSSFile:        p.CSSFile,
+		Multipage:      p.Multipage,
+		ShowNavbar:     p.ShowNavbar,
+		NavbarHTML:     p.NavbarHTML,
+		IndexPage:      p.IndexPage,
+
+		Search:           p.Search,
+		SearchIndexFile:  SearchIndexFile,
+		SearchScriptFile: SearchScriptFile,
+
+		ReverseEntries:   p.ReverseEntries,
+		ReverseIndexHref: p.ReverseIndexHref,
 	}
 }
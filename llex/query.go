@@ -0,0 +1,250 @@
+package llex
+
+import (
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// Answers questions about a dictionary, such as which entries have a headword or
+// translate a word, for tools that look words up. The dictionary must not be
+// changed while a Lookup built from it is in use; build a new one instead.
+type Lookup struct {
+	dict    *Dictionary
+	entries []*Entry // Every entry and subentry, in alphabetical order.
+	index   *entryIndex
+
+	headwords map[*Entry][]string // The search-folded headword and variants of each entry.
+	reverse   map[*Entry][]string // The search-folded reverse index keys of each entry.
+	meanings  map[*Entry][]string // The search-folded words of each entry's glosses and definitions.
+}
+
+// Build a Lookup for a dictionary.
+func NewLookup(dict *Dictionary) *Lookup {
+	l := &Lookup{
+		dict:      dict,
+		index:     newEntryIndex(dict),
+		headwords: make(map[*Entry][]string),
+		reverse:   make(map[*Entry][]string),
+		meanings:  make(map[*Entry][]string),
+	}
+
+	eachEntry(dict.sortedEntries(), func(entry *Entry) {
+		l.entries = append(l.entries, entry)
+
+		headwords := []string{searchFold(entry.Word)}
+		for _, variant := range entry.Variants {
+			headwords = append(headwords, searchFold(variant))
+		}
+		l.headwords[entry] = headwords
+
+		for _, key := range reverseKeys(entry) {
+			l.reverse[entry] = append(l.reverse[entry], searchFold(key))
+		}
+		l.meanings[entry] = strings.Fields(searchMeanings(entry))
+	})
+
+	return l
+}
+
+// Every entry and subentry, in alphabetical order, for which keep returns true. All
+// of them are returned if keep is nil.
+func (l *Lookup) Entries(keep func(entry *Entry) bool) []*Entry {
+	entries := make([]*Entry, 0)
+	for _, entry := range l.entries {
+		if keep == nil || keep(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// The entry with an ID, if there is one.
+func (l *Lookup) ByID(id string) (*Entry, bool) {
+	entry, ok := l.index.byID[id]
+	return entry, ok
+}
+
+// The entries with a headword or variant, compared as described by
+// Dictionary.foldWord, in order of homograph number.
+func (l *Lookup) Headword(word string) []*Entry {
+	word = l.dict.foldWord(strings.TrimSpace(word))
+	return l.Entries(func(entry *Entry) bool {
+		if l.dict.foldWord(entry.Word) == word {
+			return true
+		}
+		for _, variant := range entry.Variants {
+			if l.dict.foldWord(variant) == word {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// A random entry for which keep returns true, or nil if there are none. Any entry
+// may be returned if keep is nil.
+func (l *Lookup) Random(keep func(entry *Entry) bool) *Entry {
+	entries := l.Entries(keep)
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries[rand.IntN(len(entries))]
+}
+
+// How well a search matched an entry. Matches are compared by kind, then by
+// distance, then by which headword matched, and lower values are better.
+type searchRank struct {
+	kind     int // What kind of match it was, such as exact or prefix.
+	distance int // The number of edits between the search and the headword.
+	variant  int // 0 for the entry's headword, and 1 onwards for its variants.
+}
+
+func (r searchRank) less(other searchRank) bool {
+	if r.kind != other.kind {
+		return r.kind < other.kind
+	}
+	if r.distance != other.distance {
+		return r.distance < other.distance
+	}
+	return r.variant < other.variant
+}
+
+// The kinds of search matches, best first.
+const (
+	matchExact = iota
+	matchPrefix
+	matchWord // A whole word of a gloss or definition, for reverse searches.
+	matchClose
+)
+
+// An entry matched by a search, and how well it matched.
+type rankedEntry struct {
+	entry *Entry
+	rank  searchRank
+}
+
+// Sort matches by rank, keeping entries with the same rank in alphabetical order,
+// and return at most limit of them. There is no limit if limit is 0 or less.
+func rankedEntries(matches []*rankedEntry, limit int) []*Entry {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].rank.less(matches[j].rank)
+	})
+
+	entries := make([]*Entry, 0, len(matches))
+	for _, match := range matches {
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+		entries = append(entries, match.entry)
+	}
+	return entries
+}
+
+// The number of single-letter insertions, deletions and substitutions needed to
+// turn a into b.
+func editDistance(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// The most edits a headword can be from a search for it to still match: none for
+// very short searches, and then one for every three letters.
+func maxEditDistance(query []rune) int {
+	if len(query) < 3 {
+		return 0
+	}
+	return len(query) / 3
+}
+
+// Search the headwords and variants of the dictionary, ignoring case and
+// diacritics, returning at most limit entries. Exact matches come first, then
+// headwords that start with query, and then headwords a few letters away from it,
+// closest first, so that misspelt searches still find the word.
+func (l *Lookup) Search(query string, limit int) []*Entry {
+	query = searchFold(strings.TrimSpace(query))
+	if query == "" {
+		return make([]*Entry, 0)
+	}
+	queryRunes := []rune(query)
+	maxDistance := maxEditDistance(queryRunes)
+
+	var matches []*rankedEntry
+	for _, entry := range l.entries {
+		var best *searchRank
+		for i, headword := range l.headwords[entry] {
+			r := searchRank{variant: i}
+			switch {
+			case headword == query:
+				r.kind = matchExact
+			case strings.HasPrefix(headword, query):
+				r.kind = matchPrefix
+			default:
+				r.kind = matchClose
+				r.distance = editDistance(queryRunes, []rune(headword))
+				if r.distance > maxDistance {
+					continue
+				}
+			}
+			if best == nil || r.less(*best) {
+				best = &r
+			}
+		}
+		if best != nil {
+			matches = append(matches, &rankedEntry{entry, *best})
+		}
+	}
+
+	return rankedEntries(matches, limit)
+}
+
+// Search the meanings of the dictionary's entries, for translating from the gloss
+// language, ignoring case and diacritics. Entries with a gloss or reverse gloss
+// that is the whole query come first, and then those with a gloss or definition
+// containing it as a word. At most limit entries are returned.
+func (l *Lookup) Reverse(query string, limit int) []*Entry {
+	query = searchFold(strings.TrimSpace(query))
+	if query == "" {
+		return make([]*Entry, 0)
+	}
+
+	var matches []*rankedEntry
+	for _, entry := range l.entries {
+		kind := -1
+		for _, key := range l.reverse[entry] {
+			if key == query {
+				kind = matchExact
+				break
+			}
+		}
+		if kind == -1 {
+			for _, word := range l.meanings[entry] {
+				if word == query {
+					kind = matchWord
+					break
+				}
+			}
+		}
+		if kind != -1 {
+			matches = append(matches, &rankedEntry{entry, searchRank{kind: kind}})
+		}
+	}
+
+	return rankedEntries(matches, limit)
+}
@@ -0,0 +1,37 @@
+package llex
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchRanking(t *testing.T) {
+	dict := &Dictionary{Entries: []*Entry{
+		{Word: "kanata"},
+		{Word: "kilomu"},
+		{Word: "qqq", Variants: []string{"x", "y", "kelomo"}},
+		{Word: "zzz", Variants: []string{"x", "y", "kana"}},
+	}}
+	AssignEntryIDs(dict)
+	lookup := NewLookup(dict)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// An exact match on a variant ranks above a prefix match on a headword.
+		{"kana", []string{"zzz", "kanata"}},
+		// A closer match on a variant ranks above a further one on a headword.
+		{"kelomi", []string{"qqq", "kilomu"}},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, entry := range lookup.Search(test.query, 0) {
+			got = append(got, entry.Word)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package llex
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// An option understood by an import or export format, passed to it through
+// ImportParams.Options or ExportParams.Options.
+type FormatOption struct {
+	Name  string `json:"name"`
+	Usage string `json:"usage"`
+}
+
+// Information about an import or export format.
+type FormatInfo struct {
+	Name        string         `json:"name"` // Name used to select the format, such as "lp".
+	Description string         `json:"description"`
+	Extensions  []string       `json:"extensions,omitempty"` // File extensions, including the leading dot.
+	Options     []FormatOption `json:"options,omitempty"`
+}
+
+// A format that dictionaries can be imported from.
+type Importer interface {
+	Info() *FormatInfo
+	Import(r io.Reader, params *ImportParams) (*Dictionary, *ImportReport, error)
+}
+
+// A format that dictionaries can be exported to.
+type Exporter interface {
+	Info() *FormatInfo
+	Export(w io.Writer, params *ExportParams) error
+}
+
+// An Exporter that writes a directory of files, such as a website, instead of a
+// single file. Its Export method may return an error.
+type DirectoryExporter interface {
+	Exporter
+	ExportDirectory(dir string, params *ExportParams) error
+}
+
+var (
+	registryMutex sync.RWMutex
+	importers     []Importer
+	exporters     []Exporter
+)
+
+// Make an import format available under its name. It panics if a format with the
+// same name is already registered.
+func RegisterImporter(importer Importer) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, registered := range importers {
+		if registered.Info().Name == importer.Info().Name {
+			panic("llex: import format '" + importer.Info().Name + "' registered twice")
+		}
+	}
+	importers = append(importers, importer)
+}
+
+// Make an export format available under its name. It panics if a format with the
+// same name is already registered.
+func RegisterExporter(exporter Exporter) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, registered := range exporters {
+		if registered.Info().Name == exporter.Info().Name {
+			panic("llex: export format '" + exporter.Info().Name + "' registered twice")
+		}
+	}
+	exporters = append(exporters, exporter)
+}
+
+// All registered import formats, in the order they were registered.
+func Importers() []Importer {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	return append([]Importer(nil), importers...)
+}
+
+// All registered export formats, in the order they were registered.
+func Exporters() []Exporter {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	return append([]Exporter(nil), exporters...)
+}
+
+// Find an import format by name.
+func LookupImporter(name string) (Importer, bool) {
+	for _, importer := range Importers() {
+		if importer.Info().Name == name {
+			return importer, true
+		}
+	}
+	return nil, false
+}
+
+// Find an export format by name.
+func LookupExporter(name string) (Exporter, bool) {
+	for _, exporter := range Exporters() {
+		if exporter.Info().Name == name {
+			return exporter, true
+		}
+	}
+	return nil, false
+}
+
+// An Importer backed by a function.
+type importerFunc struct {
+	info     *FormatInfo
+	importFn func(io.Reader, *ImportParams) (*Dictionary, *ImportReport, error)
+	sniffFn  func([]byte) bool
+}
+
+func (f *importerFunc) Info() *FormatInfo { return f.info }
+
+func (f *importerFunc) Sniff(head []byte) bool {
+	return f.sniffFn != nil && f.sniffFn(head)
+}
+
+func (f *importerFunc) Import(r io.Reader, params *ImportParams) (*Dictionary, *ImportReport, error) {
+	return f.importFn(r, params)
+}
+
+// An Exporter backed by a function.
+type exporterFunc struct {
+	info     *FormatInfo
+	exportFn func(io.Writer, *ExportParams) error
+}
+
+func (f *exporterFunc) Info() *FormatInfo { return f.info }
+
+func (f *exporterFunc) Export(w io.Writer, params *ExportParams) error {
+	return f.exportFn(w, params)
+}
+
+// The static website export, which writes a directory.
+type websiteExporter struct{}
+
+var websiteFormatInfo = &FormatInfo{
+	Name:        "website",
+	Description: "Static website with one page per letter",
+	Options: []FormatOption{{
+		Name:  "split",
+		Usage: "Number of letters to split pages by: 1, the default, or 2 for a page per pair of letters in large lexicons",
+	}, reverseIndexFormatOption},
+}
+
+var reverseIndexFormatOption = FormatOption{
+	Name:  "reverse-index",
+	Usage: "Whether to include an index from the gloss language back to the lexicon's language: true, the default, or false",
+}
+
+func (websiteExporter) Info() *FormatInfo { return websiteFormatInfo }
+
+func (websiteExporter) Export(w io.Writer, params *ExportParams) error {
+	return ErrDirectoryExport
+}
+
+func (websiteExporter) ExportDirectory(dir string, params *ExportParams) error {
+	params.OutputPath = dir
+	return ExportStaticHTML(params)
+}
+
+// Returned by the Export method of a DirectoryExporter that can only write
+// directories.
+var ErrDirectoryExport = errors.New("format can only be exported to a directory")
+
+var columnsOption = FormatOption{
+	Name:  "columns",
+	Usage: "Comma-separated list of the field in each column, such as word,pos,definitions",
+}
+
+func init() {
+	RegisterImporter(&importerFunc{
+		info: &FormatInfo{
+			Name:        "lp",
+			Description: "Lexique Pro and Toolbox databases (Standard Format Markers)",
+			Extensions:  []string{".db", ".sfm", ".txt"},
+		},
+		importFn: ImportFromLexiqueProReader,
+		sniffFn:  sniffSFM,
+	})
+	RegisterImporter(&importerFunc{
+		info: &FormatInfo{
+			Name:        "lift",
+			Description: "Lexicon Interchange FormaT, used by FieldWorks and WeSay",
+			Extensions:  []string{".lift"},
+		},
+		importFn: ImportFromLIFTReader,
+		sniffFn:  sniffLIFT,
+	})
+	RegisterImporter(&importerFunc{
+		info: &FormatInfo{
+			Name:        "csv",
+			Description: "Comma-separated values, one entry per row",
+			Extensions:  []string{".csv"},
+			Options:     []FormatOption{columnsOption},
+		},
+		importFn: ImportFromCSVReader,
+		sniffFn:  sniffCSV,
+	})
+	RegisterImporter(&importerFunc{
+		info: &FormatInfo{
+			Name:        "tsv",
+			Description: "Tab-separated values, one entry per row",
+			Extensions:  []string{".tsv", ".tab"},
+			Options:     []FormatOption{columnsOption},
+		},
+		importFn: ImportFromTSVReader,
+		sniffFn:  sniffTSV,
+	})
+	RegisterImporter(&importerFunc{
+		info: &FormatInfo{
+			Name:        "json",
+			Description: "An llex lexicon, as written by import",
+			Extensions:  []string{".json"},
+		},
+		importFn: importJSON,
+		sniffFn:  sniffJSON,
+	})
+
+	RegisterExporter(&exporterFunc{
+		info: &FormatInfo{
+			Name:        "html",
+			Description: "Single-file HTML",
+			Extensions:  []string{".html", ".htm"},
+			Options:     []FormatOption{reverseIndexFormatOption},
+		},
+		exportFn: ExportSinglePageHTMLTo,
+	})
+	RegisterExporter(websiteExporter{})
+	RegisterExporter(&exporterFunc{
+		info: &FormatInfo{
+			Name:        "sfm",
+			Description: "Standard Format Markers (MDF), for Lexique Pro and Toolbox",
+			Extensions:  []string{".db", ".sfm"},
+		},
+		exportFn: ExportSFMTo,
+	})
+	RegisterExporter(&exporterFunc{
+		info: &FormatInfo{
+			Name:        "lift",
+			Description: "Lexicon Interchange FormaT, for FieldWorks and WeSay",
+			Extensions:  []string{".lift"},
+		},
+		exportFn: ExportLIFTTo,
+	})
+	RegisterExporter(&exporterFunc{
+		info: &FormatInfo{
+			Name:        "csv",
+			Description: "Comma-separated values, one entry per row",
+			Extensions:  []string{".csv"},
+			Options:     []FormatOption{columnsOption},
+		},
+		exportFn: ExportCSVTo,
+	})
+	RegisterExporter(&exporterFunc{
+		info: &FormatInfo{
+			Name:        "tsv",
+			Description: "Tab-separated values, one entry per row",
+			Extensions:  []string{".tsv", ".tab"},
+			Options:     []FormatOption{columnsOption},
+		},
+		exportFn: ExportTSVTo,
+	})
+}
@@ -0,0 +1,111 @@
+package llex
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Finds entries by ID or by headword and homograph number.
+type entryIndex struct {
+	dict       *Dictionary
+	byID       map[string]*Entry
+	byHeadword map[headwordKey]*Entry // By folded headword. See Dictionary.foldWord.
+	homographs map[string]int         // Number of entries with each folded headword.
+}
+
+func newEntryIndex(dict *Dictionary) *entryIndex {
+	index := &entryIndex{
+		dict:       dict,
+		byID:       make(map[string]*Entry),
+		byHeadword: make(map[headwordKey]*Entry),
+		homographs: make(map[string]int),
+	}
+	eachEntry(dict.Entries, func(entry *Entry) {
+		if _, ok := index.byID[entry.ID]; !ok && entry.ID != "" {
+			index.byID[entry.ID] = entry
+		}
+		word := dict.foldWord(entry.Word)
+		index.byHeadword[headwordKey{word, entry.Homograph}] = entry
+		index.homographs[word]++
+	})
+	return index
+}
+
+// Find the entry a relation refers to, by its target ID if it has one and
+// otherwise by its headword. If there is no such entry, the reason is returned
+// instead.
+func (index *entryIndex) target(relation *Relation) (*Entry, string) {
+	if entry, ok := index.byID[relation.TargetID]; ok {
+		return entry, ""
+	}
+	if relation.Target == "" {
+		return nil, fmt.Sprintf("no entry has the ID '%s'", relation.TargetID)
+	}
+
+	word := index.dict.foldWord(relation.Target)
+	if entry, ok := index.byHeadword[headwordKey{word, relation.Homograph}]; ok {
+		return entry, ""
+	}
+
+	switch count := index.homographs[word]; {
+	case count > 1 && relation.Homograph == 0:
+		return nil, fmt.Sprintf("%d entries have the headword '%s'; give a homograph number", count, relation.Target)
+	case relation.Homograph > 0:
+		return nil, fmt.Sprintf("no entry is homograph %d of '%s'", relation.Homograph, relation.Target)
+	}
+	return nil, fmt.Sprintf("no entry has the headword '%s'", relation.Target)
+}
+
+// A relation whose target is not in the dictionary.
+type DanglingRelation struct {
+	Path     string // Path to the relation, such as entries[3].relations[0].
+	Entry    *Entry // The entry the relation belongs to.
+	Relation *Relation
+	Reason   string
+}
+
+func (d *DanglingRelation) Error() string {
+	return d.Path + ": " + d.Reason
+}
+
+// Find the relations whose targets are not in the dictionary, and if update is set,
+// bring the target ID, headword and homograph number of every other relation up to
+// date with the entry it refers to.
+func resolveRelations(dict *Dictionary, update bool) []*DanglingRelation {
+	index := newEntryIndex(dict)
+	var dangling []*DanglingRelation
+
+	walkEntries(dict.Entries, "entries", func(entry *Entry, path string) {
+		for i, relation := range entry.Relations {
+			target, reason := index.target(relation)
+			if target == nil {
+				dangling = append(dangling, &DanglingRelation{
+					Path:     path + ".relations[" + strconv.Itoa(i) + "]",
+					Entry:    entry,
+					Relation: relation,
+					Reason:   reason,
+				})
+				continue
+			}
+
+			if update {
+				relation.TargetID = target.ID
+				relation.Target = target.Word
+				relation.Homograph = target.Homograph
+			}
+		}
+	})
+
+	return dangling
+}
+
+// Link each relation to the entry it refers to, filling in its target ID from its
+// headword and homograph number, or the other way around. Since the ID takes
+// precedence, relations follow their target when its headword changes.
+//
+// The relations whose targets could not be found are left as they are and
+// returned. Dictionaries returned by ReadDictionary and by importers have already
+// been through ResolveRelations.
+func ResolveRelations(dict *Dictionary) []*DanglingRelation {
+	return resolveRelations(dict, true)
+}
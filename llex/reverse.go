@@ -0,0 +1,139 @@
+package llex
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Words left out of the reverse index when it is built from definitions, since
+// nobody looks them up.
+var reverseStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "one": true, "or": true, "something": true, "someone": true,
+	"that": true, "the": true, "their": true, "this": true, "to": true,
+	"used": true, "was": true, "which": true, "who": true, "with": true,
+}
+
+// An entry of the reverse index: a word in the gloss language, and the entries
+// that translate it.
+type ReverseIndexEntry struct {
+	Key     string
+	Targets []*ReverseIndexTarget
+}
+
+// An entry that a word in the reverse index translates to.
+type ReverseIndexTarget struct {
+	Entry *Entry
+	Href  string // Link to the entry, on its main entry's page if it is a subentry. Empty if the entry is on no page.
+}
+
+// The keys an entry is listed under in the reverse index. Glosses are used whole,
+// since they are already short translations. Definitions are split into words,
+// leaving out common words, unless they have reverse glosses, which are used
+// instead.
+func reverseKeys(entry *Entry) []string {
+	var keys []string
+	for _, sense := range entry.Senses {
+		for _, gloss := range sense.Glosses {
+			keys = append(keys, strings.TrimSpace(gloss))
+		}
+
+		for _, def := range sense.Definitions {
+			if len(def.ReverseGlosses) > 0 {
+				for _, gloss := range def.ReverseGlosses {
+					keys = append(keys, strings.TrimSpace(gloss))
+				}
+				continue
+			}
+
+			for _, word := range strings.FieldsFunc(def.Text, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+			}) {
+				word = strings.Trim(strings.ToLower(word), "'-")
+				if len([]rune(word)) > 1 && !reverseStopWords[word] && !unicode.IsDigit([]rune(word)[0]) {
+					keys = append(keys, word)
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// Build the reverse index of a dictionary, from the gloss language back to the
+// dictionary's language, sorted by key. Keys that only differ in case are merged,
+// and each key lists its entries in the dictionary's order. Entries link to where
+// linker says they are.
+func buildReverseIndex(dict *Dictionary, linker *htmlLinker) []*ReverseIndexEntry {
+	var index []*ReverseIndexEntry
+	byKey := make(map[string]*ReverseIndexEntry)
+	listed := make(map[*ReverseIndexEntry]map[*Entry]bool)
+
+	eachEntry(dict.sortedEntries(), func(entry *Entry) {
+		for _, key := range reverseKeys(entry) {
+			if key == "" {
+				continue
+			}
+
+			folded := strings.ToLower(key)
+			reverse, ok := byKey[folded]
+			if !ok {
+				reverse = &ReverseIndexEntry{Key: key}
+				byKey[folded] = reverse
+				listed[reverse] = make(map[*Entry]bool)
+				index = append(index, reverse)
+			}
+
+			if !listed[reverse][entry] {
+				listed[reverse][entry] = true
+				reverse.Targets = append(reverse.Targets, &ReverseIndexTarget{Entry: entry, Href: linker.entryHref(entry)})
+			}
+		}
+	})
+
+	// The keys are in the gloss language, which the dictionary's alphabet is not
+	// for.
+	collator := NewCollator(nil)
+	sort.SliceStable(index, func(i, j int) bool {
+		return collator.Compare(index[i].Key, index[j].Key) < 0
+	})
+	return index
+}
+
+// The built-in reverse index template. See Templates.
+var reverseTemplate = `<div class="reverse-entry"><span class="reverse-key">{{.Key}}</span>: {{range $i, $t := .Targets}}{{if $i}}, {{end}}{{if $t.Href}}<a href="{{$t.Href}}">{{end}}<span class="headword">{{$t.Entry.Word}}</span>{{if $t.Entry.Homograph}}<sup class="homograph">{{$t.Entry.Homograph}}</sup>{{end}}{{if $t.Href}}</a>{{end}}{{if $t.Entry.POS}} <i class="part-of-speech">{{$t.Entry.POS}}</i>{{end}}{{end}}</div>`
+
+// Render the entries of a reverse index with the reverse template.
+func generateReverseHTML(source string, index []*ReverseIndexEntry) ([]template.HTML, error) {
+	t, err := template.New("reverse").Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverseHTML []template.HTML
+	for _, reverse := range index {
+		var html bytes.Buffer
+		if err := t.Execute(&html, reverse); err != nil {
+			return nil, err
+		}
+		reverseHTML = append(reverseHTML, template.HTML(html.String()))
+	}
+	return reverseHTML, nil
+}
+
+// Read the "reverse-index" option, which turns off the reverse index when it is
+// "false".
+func reverseIndexOption(options map[string]string) (bool, error) {
+	switch options["reverse-index"] {
+	case "", "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("reverse-index must be true or false, not '%s'", options["reverse-index"])
+}
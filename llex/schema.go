@@ -0,0 +1,191 @@
+package llex
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Identifier of the JSON Schema draft that JSONSchema follows. Draft 7 is the one
+// supported by the most editors.
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// The name of a struct field in JSON, or "" if it is not written to JSON.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if !field.IsExported() || name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// The JSON fields of a struct type, by name.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonFieldName(t.Field(i)); name != "" {
+			fields[name] = t.Field(i)
+		}
+	}
+	return fields
+}
+
+// Builds a JSON Schema from Go types, putting each struct type in the definitions
+// so that recursive types such as Entry can refer to themselves.
+type schemaBuilder struct {
+	definitions map[string]any
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := b.definitions[t.Name()]; !ok {
+			// Reserve the name first, in case the type refers to itself.
+			b.definitions[t.Name()] = nil
+			b.definitions[t.Name()] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/definitions/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+// The schema of a struct type. Fields without omitempty are required, and no
+// other fields are allowed.
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for name, field := range jsonFields(t) {
+		properties[name] = b.schema(field.Type)
+		if !strings.Contains(field.Tag.Get("json"), ",omitempty") {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// Generate a JSON Schema describing the llex lexicon format, so that editors can
+// offer completion and validation for lexicon files. Files can refer to the schema
+// with a "$schema" key.
+func JSONSchema() ([]byte, error) {
+	b := &schemaBuilder{definitions: make(map[string]any)}
+	root := b.object(reflect.TypeOf(Dictionary{}))
+
+	root["$schema"] = jsonSchemaDraft
+	root["title"] = "llex lexicon"
+	root["description"] = fmt.Sprintf("A lexicon managed by the Lemurian Lexicon Manager, format version %d.", CurrentFormatVersion)
+	root["definitions"] = b.definitions
+
+	properties := root["properties"].(map[string]any)
+	properties["formatVersion"] = map[string]any{
+		"type":    "integer",
+		"minimum": 0,
+		"maximum": CurrentFormatVersion,
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// Returned when reading a dictionary that has fields llex does not know about.
+type UnknownFieldsError struct {
+	Fields []string // Paths to the unknown fields, such as entries[3].partofspeech.
+	// Suggested replacements, by path, for fields that only differ from a known
+	// field by case.
+	Suggestions map[string]string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, path := range e.Fields {
+		messages[i] = fmt.Sprintf("unknown field %q", path)
+		if suggestion, ok := e.Suggestions[path]; ok {
+			messages[i] += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Find fields of a decoded JSON document that do not exist in the type t.
+func findUnknownFields(value any, t reflect.Type, path string, err *UnknownFieldsError) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		fields := jsonFields(t)
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+
+			field, ok := fields[key]
+			if ok {
+				findUnknownFields(v[key], field.Type, fieldPath, err)
+				continue
+			}
+
+			err.Fields = append(err.Fields, fieldPath)
+			for name := range fields {
+				if strings.EqualFold(name, key) {
+					err.Suggestions[fieldPath] = name
+				}
+			}
+		}
+	case []any:
+		if t.Kind() != reflect.Slice {
+			return
+		}
+		for i, item := range v {
+			findUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), err)
+		}
+	}
+}
+
+// Check a decoded lexicon document for fields that llex does not know about.
+func checkUnknownFields(doc map[string]any) error {
+	err := &UnknownFieldsError{Suggestions: make(map[string]string)}
+	findUnknownFields(doc, reflect.TypeOf(Dictionary{}), "", err)
+	if len(err.Fields) > 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package llex
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// IDs are assigned when a lexicon is read, so entries added by hand need not
+// have one.
+func TestJSONSchemaEntryRequired(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Definitions map[string]struct {
+			Required []string `json:"required"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"partOfSpeech", "senses", "word"}
+	if got := schema.Definitions["Entry"].Required; !slices.Equal(got, want) {
+		t.Errorf("Entry requires %v, want %v", got, want)
+	}
+}
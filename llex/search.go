@@ -0,0 +1,225 @@
+package llex
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The files of a website's search, written next to its pages. The index is a
+// script rather than JSON so that it can be loaded from the file system, where
+// browsers do not allow pages to fetch files.
+const (
+	SearchIndexFile  = "search-index.js"
+	SearchScriptFile = "search.js"
+)
+
+// The longest summary of an entry's meaning shown in search results, in characters.
+const searchSummaryLength = 100
+
+// Fold text for searching: lower case, without diacritics, so that searches for
+// "e" also find "é".
+func searchFold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// An entry in a website's search index. The field names are kept short, as the
+// index is downloaded with the website.
+type searchIndexEntry struct {
+	Word      string `json:"w"`
+	Homograph int    `json:"h,omitempty"`
+	POS       string `json:"p,omitempty"`
+	Href      string `json:"u"`
+	Summary   string `json:"d,omitempty"` // The entry's glosses or definitions, shortened.
+	Headwords string `json:"k"`           // The folded headword and variants, separated by spaces.
+	Meanings  string `json:"r,omitempty"` // The folded words of every gloss and definition, for reverse searches.
+}
+
+// Summarise the meaning of an entry for search results, preferring glosses to
+// definitions.
+func searchSummary(entry *Entry) string {
+	var parts []string
+	for _, sense := range entry.Senses {
+		if len(sense.Glosses) > 0 {
+			parts = append(parts, strings.Join(sense.Glosses, ", "))
+			continue
+		}
+		for _, def := range sense.Definitions {
+			parts = append(parts, def.Text)
+		}
+	}
+
+	summary := []rune(strings.Join(parts, "; "))
+	if len(summary) > searchSummaryLength {
+		return strings.TrimSpace(string(summary[:searchSummaryLength-1])) + "…"
+	}
+	return string(summary)
+}
+
+// The folded words in the glosses and definitions of an entry, each only once.
+func searchMeanings(entry *Entry) string {
+	var words []string
+	seen := make(map[string]bool)
+	add := func(text string) {
+		for _, word := range strings.FieldsFunc(searchFold(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+		}) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+
+	for _, sense := range entry.Senses {
+		for _, gloss := range sense.Glosses {
+			add(gloss)
+		}
+		for _, def := range sense.Definitions {
+			add(def.Text)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Build the search index of a website as a script that sets LLEX_SEARCH_INDEX.
+// Entries are listed in alphabetical order, with subentries after their main
+// entry, and link to the page they are shown on.
+func buildSearchIndex(entries []*Entry, linker *htmlLinker) (string, error) {
+	index := make([]*searchIndexEntry, 0)
+	eachEntry(entries, func(entry *Entry) {
+		headwords := []string{searchFold(entry.Word)}
+		for _, variant := range entry.Variants {
+			headwords = append(headwords, searchFold(variant))
+		}
+
+		index = append(index, &searchIndexEntry{
+			Word:      entry.Word,
+			Homograph: entry.Homograph,
+			POS:       entry.POS,
+			Href:      linker.entryHref(entry),
+			Summary:   searchSummary(entry),
+			Headwords: strings.Join(headwords, " "),
+			Meanings:  searchMeanings(entry),
+		})
+	})
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return "", err
+	}
+	return "var LLEX_SEARCH_INDEX = " + string(data) + ";\n", nil
+}
+
+// Searches the index in search-index.js as the reader types. Headwords are
+// matched by prefix, and then glosses and definitions word by word, ignoring case
+// and diacritics.
+var SearchScript = `(function () {
+	"use strict";
+
+	var MAX_RESULTS = 50;
+
+	function fold(s) {
+		return s.toLowerCase().normalize("NFD").replace(/\p{Mn}/gu, "").trim();
+	}
+
+	// Whether one of the space-separated words in text starts with query.
+	function hasWordPrefix(text, query) {
+		return (" " + text).indexOf(" " + query) !== -1;
+	}
+
+	function rank(entry, query) {
+		var headword = entry.k.split(" ")[0];
+		if (headword === query) {
+			return 0;
+		}
+		if (headword.indexOf(query) === 0) {
+			return 1;
+		}
+		if (hasWordPrefix(entry.k, query)) {
+			return 2;
+		}
+		if (entry.r && hasWordPrefix(entry.r, query)) {
+			return 3;
+		}
+		return -1;
+	}
+
+	function search(query) {
+		var matches = [];
+		query = fold(query);
+		if (query === "") {
+			return matches;
+		}
+		LLEX_SEARCH_INDEX.forEach(function (entry, i) {
+			var r = rank(entry, query);
+			if (r !== -1) {
+				matches.push({ entry: entry, rank: r, order: i });
+			}
+		});
+		matches.sort(function (a, b) {
+			return a.rank - b.rank || a.order - b.order;
+		});
+		return matches.slice(0, MAX_RESULTS).map(function (match) {
+			return match.entry;
+		});
+	}
+
+	function element(tag, className, text) {
+		var el = document.createElement(tag);
+		if (className) {
+			el.className = className;
+		}
+		if (text) {
+			el.textContent = text;
+		}
+		return el;
+	}
+
+	function render(list, entries, query) {
+		list.textContent = "";
+		if (entries.length === 0 && fold(query) !== "") {
+			list.appendChild(element("li", "search-none", "No matches."));
+			return;
+		}
+		entries.forEach(function (entry) {
+			var item = element("li", "search-result");
+			var link = element("a", "headword", entry.w);
+			link.href = entry.u;
+			if (entry.h) {
+				link.appendChild(element("sup", "homograph", String(entry.h)));
+			}
+			item.appendChild(link);
+			if (entry.p) {
+				item.appendChild(document.createTextNode(" "));
+				item.appendChild(element("i", "part-of-speech", entry.p));
+			}
+			if (entry.d) {
+				item.appendChild(document.createTextNode(" "));
+				item.appendChild(element("span", "summary", entry.d));
+			}
+			list.appendChild(item);
+		});
+	}
+
+	var input = document.getElementById("search");
+	var list = document.getElementById("search-results");
+	if (!input || !list || typeof LLEX_SEARCH_INDEX === "undefined") {
+		return;
+	}
+	input.addEventListener("input", function () {
+		render(list, search(input.value), input.value);
+	});
+	if (input.value) {
+		render(list, search(input.value), input.value);
+	}
+})();
+`
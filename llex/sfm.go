@@ -0,0 +1,65 @@
+package llex
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// A single field of a Standard Format Marker (SFM) file, such as the ones used by
+// Lexique Pro and Toolbox: a backslash marker followed by its value.
+type sfmField struct {
+	Marker string // The marker, including the leading backslash (e.g. `\lx`).
+	Value  string
+	Line   int // Line number the field starts on, starting from 1.
+}
+
+// Read all fields from an SFM file.
+//
+// Lines that do not begin with a marker continue the value of the previous field,
+// as Toolbox wraps long values over several lines. Blank lines are ignored, as are
+// header markers such as `\_sh`, which describe the database rather than its entries.
+func readSFMFields(r io.Reader) ([]*sfmField, error) {
+	var fields []*sfmField
+	var last *sfmField
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(text, `\`) {
+			if last != nil {
+				last.Value += " " + strings.TrimSpace(text)
+			}
+			continue
+		}
+
+		// The marker ends at the first space or tab, everything after it is the value.
+		field := &sfmField{Marker: text, Line: lineNumber}
+		if i := strings.IndexAny(text, " \t"); i != -1 {
+			field.Marker = text[:i]
+			field.Value = text[i+1:]
+		}
+
+		if strings.HasPrefix(field.Marker, `\_`) {
+			last = nil
+			continue
+		}
+
+		fields = append(fields, field)
+		last = field
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,255 @@
+package llex
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The letters of a language in alphabetical order, used to sort entries.
+type Alphabet struct {
+	// Letters in alphabetical order, such as "a", "ng" and "ʔ". Letters written with
+	// several characters are sorted as one. Case is ignored.
+	Letters []string `json:"letters"`
+	// Characters that are skipped when sorting, such as hyphens and apostrophes.
+	Ignorable []string `json:"ignorable,omitempty"`
+}
+
+// Compares words by the alphabet of a language.
+//
+// Words are split into letters, preferring the longest letter of the alphabet at
+// each position, and compared letter by letter. Letters are never split from the
+// combining marks that follow them, so a letter with a diacritic that is not in
+// the alphabet sorts just after the letter without it. Other characters that are
+// not in the alphabet sort after all of its letters, by their code points. Without
+// an alphabet, words are compared by the code points of their characters, ignoring
+// case. Words and letters are compared in NFC, so the same letter typed as one
+// character or with a combining mark sorts the same.
+type Collator struct {
+	order     map[string]int // Position of each letter in the alphabet.
+	ignorable map[string]bool
+	longest   int // Length in characters of the longest letter or ignorable character.
+}
+
+// Create a Collator for an alphabet, which may be nil.
+func NewCollator(alphabet *Alphabet) *Collator {
+	c := &Collator{
+		order:     make(map[string]int),
+		ignorable: make(map[string]bool),
+		longest:   1,
+	}
+	if alphabet == nil {
+		return c
+	}
+
+	for i, letter := range alphabet.Letters {
+		letter = norm.NFC.String(strings.ToLower(letter))
+		if _, ok := c.order[letter]; !ok && letter != "" {
+			c.order[letter] = i
+		}
+		c.longest = max(c.longest, len([]rune(letter)))
+	}
+	for _, ignorable := range alphabet.Ignorable {
+		ignorable = norm.NFC.String(strings.ToLower(ignorable))
+		c.ignorable[ignorable] = true
+		c.longest = max(c.longest, len([]rune(ignorable)))
+	}
+
+	return c
+}
+
+// The Collator for the dictionary's alphabet.
+func (d *Dictionary) Collator() *Collator {
+	return NewCollator(d.Alphabet)
+}
+
+// Whether a character is drawn as part of the character before it, such as a
+// combining diacritic.
+func continuesGrapheme(previous rune, r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me) || r == '\u200d' || previous == '\u200d'
+}
+
+// Split a string into the characters a reader would see, keeping combining marks
+// with the character before them.
+func graphemeClusters(s string) []string {
+	var clusters []string
+	var previous rune
+	for i, r := range s {
+		if len(clusters) > 0 && continuesGrapheme(previous, r) {
+			clusters[len(clusters)-1] += string(r)
+		} else {
+			clusters = append(clusters, s[i:i+len(string(r))])
+		}
+		previous = r
+	}
+	return clusters
+}
+
+// Split a word into letters of the alphabet, in lower case and NFC. Characters that
+// are not part of a letter are returned on their own, along with any combining
+// marks, and ignorable characters are left out.
+func (c *Collator) Letters(word string) []string {
+	letters, _ := c.casedLetters(word)
+	return letters
+}
+
+// Like Letters, but also return each letter as it is written in the word, in NFC
+// and keeping its case.
+func (c *Collator) casedLetters(word string) (letters []string, cased []string) {
+	// Lower the case of each cluster separately, so that the letters in lower
+	// case and as written are made of the same clusters.
+	clusters := graphemeClusters(norm.NFC.String(word))
+	lowered := make([]string, len(clusters))
+	for i, cluster := range clusters {
+		lowered[i] = norm.NFC.String(strings.ToLower(cluster))
+	}
+
+	for i := 0; i < len(clusters); {
+		// Each cluster has at least one character, so no letter can be made of
+		// more clusters than the longest letter has characters.
+		n := min(c.longest, len(clusters)-i)
+		for ; n > 1; n-- {
+			candidate := strings.Join(lowered[i:i+n], "")
+			if _, ok := c.order[candidate]; ok || c.ignorable[candidate] {
+				break
+			}
+		}
+
+		letter := strings.Join(lowered[i:i+n], "")
+		if !c.ignorable[letter] {
+			letters = append(letters, letter)
+			cased = append(cased, strings.Join(clusters[i:i+n], ""))
+		}
+		i += n
+	}
+
+	return letters, cased
+}
+
+// The first letter of a word, in lower case, or "" if it has none.
+func (c *Collator) FirstLetter(word string) string {
+	letters := c.Letters(word)
+	if len(letters) == 0 {
+		return ""
+	}
+	return letters[0]
+}
+
+// Whether a letter, in lower case, is in the alphabet.
+func (c *Collator) inAlphabet(letter string) bool {
+	_, ok := c.order[letter]
+	return ok
+}
+
+// Remove the diacritics from a letter, as in "é" to "e".
+func withoutDiacritics(letter string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(letter) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// The position of a letter in the alphabet. Letters that are not in the alphabet
+// take the position of the letter without their diacritics, or else of their first
+// character, if it is, and otherwise come after all of the alphabet's letters.
+func (c *Collator) position(letter string) (position int, inAlphabet bool) {
+	if position, ok := c.order[letter]; ok {
+		return position, true
+	}
+	base := withoutDiacritics(letter)
+	if position, ok := c.order[base]; ok {
+		return position, true
+	}
+	for _, r := range base {
+		if position, ok := c.order[string(r)]; ok {
+			return position, true
+		}
+		break
+	}
+	return len(c.order), false
+}
+
+func (c *Collator) compareLetters(a string, b string) int {
+	if a == b {
+		return 0
+	}
+
+	positionA, okA := c.position(a)
+	positionB, okB := c.position(b)
+	switch {
+	case okA && !okB:
+		return -1
+	case okB && !okA:
+		return 1
+	case positionA != positionB:
+		return positionA - positionB
+	}
+
+	// Letters of the alphabet come before letters with diacritics that share their
+	// position.
+	_, exactA := c.order[a]
+	_, exactB := c.order[b]
+	switch {
+	case exactA && !exactB:
+		return -1
+	case exactB && !exactA:
+		return 1
+	}
+	// Otherwise, order them by their diacritics.
+	return strings.Compare(norm.NFD.String(a), norm.NFD.String(b))
+}
+
+// Compare two words, returning a negative number if a comes first, a positive
+// number if b comes first, and zero if they are the same. Words that only differ
+// by ignorable characters or case are ordered by those, so that the order is
+// always the same.
+func (c *Collator) Compare(a string, b string) int {
+	lettersA, lettersB := c.Letters(a), c.Letters(b)
+	for i := 0; i < len(lettersA) && i < len(lettersB); i++ {
+		if result := c.compareLetters(lettersA[i], lettersB[i]); result != 0 {
+			return result
+		}
+	}
+	if result := len(lettersA) - len(lettersB); result != 0 {
+		return result
+	}
+
+	if result := strings.Compare(strings.ToLower(a), strings.ToLower(b)); result != 0 {
+		return result
+	}
+	return strings.Compare(a, b)
+}
+
+// Compare two entries for sorting, returning a negative number if a comes first,
+// a positive number if b comes first, and zero if their order does not matter.
+//
+// Entries are sorted by their headword. Entries with the same headword are then
+// ordered by homograph number and part of speech, so that the order does not
+// depend on the order they were added in.
+func (c *Collator) compareEntries(a *Entry, b *Entry) int {
+	if result := c.Compare(a.Word, b.Word); result != 0 {
+		return result
+	}
+	if result := a.Homograph - b.Homograph; result != 0 {
+		return result
+	}
+	return strings.Compare(a.POS, b.POS)
+}
+
+func sortEntries(entries []*Entry, collator *Collator) []*Entry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return collator.compareEntries(entries[i], entries[j]) < 0
+	})
+	return entries
+}
+
+// The dictionary's entries in alphabetical order. The dictionary itself is not
+// modified.
+func (d *Dictionary) sortedEntries() []*Entry {
+	return sortEntries(append([]*Entry(nil), d.Entries...), d.Collator())
+}
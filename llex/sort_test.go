@@ -0,0 +1,24 @@
+package llex
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestCollatorSortsDiacriticsAfterBaseLetter(t *testing.T) {
+	collator := NewCollator(&Alphabet{Letters: []string{"ʔ", "a", "e", "n", "ng", "t", "ts"}})
+
+	// A precomposed letter sorts the same as a letter with a combining accent, just
+	// after the letter without the accent, and letters with different accents are
+	// ordered by their accents.
+	words := []string{"tsa", "\u00e9ta", "ta", "ena", "e\u0301na", "nga", "ʔa", "\u00e8ta"}
+	sort.SliceStable(words, func(i, j int) bool {
+		return collator.Compare(words[i], words[j]) < 0
+	})
+
+	want := []string{"ʔa", "ena", "\u00e8ta", "e\u0301na", "\u00e9ta", "nga", "ta", "tsa"}
+	if !slices.Equal(words, want) {
+		t.Errorf("got %v, want %v", words, want)
+	}
+}
@@ -0,0 +1,149 @@
+package llex
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The names of the files in a template directory. See LoadTemplates.
+const (
+	PageTemplateFile    = "page.html"
+	EntryTemplateFile   = "entry.html"
+	NavbarTemplateFile  = "navbar.html"
+	ReverseTemplateFile = "reverse.html"
+	StylesheetFile      = "style.css"
+)
+
+// The templates HTML exports are rendered with, as html/template source.
+//
+//   - Page is executed with a *PageData for each page.
+//   - Entry is executed with an *Entry for each entry. It can call relationLabel
+//     with a relation type to get a label for it, and relationHref with a
+//     *Relation to get a link to its target, which is "" if the target is not in
+//     the dictionary or not on any page. Subentries can be rendered with the
+//     entry template by calling subentryHTML with each of them.
+//   - Navbar is executed with a *NavbarData for each page of a website.
+//   - Reverse is executed with a *ReverseIndexEntry for each entry of the reverse
+//     index.
+type Templates struct {
+	Page    string
+	Entry   string
+	Navbar  string
+	Reverse string
+	CSS     string // A stylesheet to use instead of the theme's, if not empty.
+}
+
+// The built-in templates.
+func DefaultTemplates() *Templates {
+	return &Templates{
+		Page:    HtmlTemplate,
+		Entry:   WordTemplate,
+		Navbar:  navbarTemplate,
+		Reverse: reverseTemplate,
+	}
+}
+
+// Load templates from a directory, using page.html, entry.html, navbar.html,
+// reverse.html and style.css where they exist and the built-in templates otherwise.
+func LoadTemplates(dir string) (*Templates, error) {
+	templates := DefaultTemplates()
+
+	files := []struct {
+		name   string
+		source *string
+		funcs  template.FuncMap
+	}{
+		{PageTemplateFile, &templates.Page, nil},
+		{EntryTemplateFile, &templates.Entry, entryTemplateFuncs("", nil)},
+		{NavbarTemplateFile, &templates.Navbar, nil},
+		{ReverseTemplateFile, &templates.Reverse, nil},
+		{StylesheetFile, &templates.CSS, nil},
+	}
+
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		*file.source = string(data)
+
+		if file.name == StylesheetFile {
+			continue
+		}
+		// Check the template now, so that mistakes in it are reported along with
+		// the file they are in.
+		if _, err := template.New(file.name).Funcs(file.funcs).Parse(*file.source); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return templates, nil
+}
+
+// The functions available to the entry template, which is given as source so that
+// subentries can be rendered with it.
+func entryTemplateFuncs(source string, linker *htmlLinker) template.FuncMap {
+	return template.FuncMap{
+		"relationLabel": relationLabel,
+		"relationHref":  linker.href,
+		"subentryHTML": func(subentry *Entry) (template.HTML, error) {
+			html, err := subentry.generateHTML(source, linker)
+			return template.HTML(html), err
+		},
+	}
+}
+
+// The data the page template is executed with.
+type PageData struct {
+	LanguageName   string
+	GlossLanguage  string          // The language entries are defined in, such as English.
+	Author         string          // Empty if not given.
+	Copyright      template.HTML   // Copyright information for the dictionary.
+	AuthorsNote    template.HTML   // Empty if not given.
+	HTMLEntries    []template.HTML // The entries on the page, already rendered with the entry template.
+	NumWords       int             // The number of entries on the page.
+	Timestamp      time.Time       // When the export started.
+	GenerationTime time.Duration
+	UseEmbeddedCSS bool         // Whether the stylesheet should be included in the page, rather than linked to.
+	CSS            template.CSS // The stylesheet to embed.
+	CSSFile        string       // The stylesheet to link to.
+	Multipage      bool         // Whether the page is part of a website with several pages.
+	ShowNavbar     bool
+	NavbarHTML     template.HTML // Rendered with the navbar template.
+	IndexPage      bool          // Whether the page is a website's index.html.
+	// Whether the page has a search box, which needs the scripts in
+	// SearchIndexFile and SearchScriptFile. See ExportStaticHTML.
+	Search           bool
+	SearchIndexFile  string
+	SearchScriptFile string
+
+	// The entries of the reverse index on the page, already rendered with the
+	// reverse template.
+	ReverseEntries []template.HTML
+	// Link to the first page of a website's reverse index, if it has one.
+	ReverseIndexHref string
+}
+
+// A link in the navigation bar of a website.
+type NavbarLink struct {
+	Name   string // The letters of the page linked to, such as "ng" or "ka".
+	Letter string // The first letter of the page linked to.
+	Href   string
+}
+
+// The data the navbar template is executed with.
+type NavbarData struct {
+	Letters []*NavbarLink // A link to the first page of each letter.
+	// Links to every page for the current letter, when pages are split by more
+	// than one letter. Otherwise it is empty.
+	Pages []*NavbarLink
+}
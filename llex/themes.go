@@ -0,0 +1,163 @@
+package llex
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The layout shared by every theme.
+var baseCSS = `
+		.dictionary ol {
+			margin: 0px;
+		}
+		body {
+			max-width: 600px;
+			margin: auto;
+			padding: 10px;
+		}
+		@media print {
+			.no-print {
+				display: none;
+			}
+		}
+		.entry {
+			break-inside: avoid;
+			margin-bottom: 8px;
+			padding-bottom: 8px;
+		}
+		.subentries {
+			margin-left: 1.5em;
+		}
+		.subentries .entry {
+			margin-bottom: 0px;
+		}
+		.auxilliary p {
+			font-size: 85%;
+			margin: 0.1%;
+		}
+		.example-text {
+			font-style: italic;
+		}
+		.semantic-domain {
+			font-size: 85%;
+		}
+		.navbar .letter {
+			padding: 5px;
+			font-size: 110%;
+		}
+		.search input {
+			box-sizing: border-box;
+			width: 100%;
+			padding: 5px;
+			font-size: 110%;
+		}
+		.search ul {
+			list-style: none;
+			padding-left: 0;
+		}
+		.search-result {
+			margin-bottom: 4px;
+		}`
+
+// Light text on a black background.
+var darkThemeCSS = `
+		a {
+			color: #acc0fb;
+		}
+		body {
+			background-color: #000;
+			color: #fff;
+		}
+		@media screen {
+			body {
+				border: 1px solid #4d4d4d;
+				border-radius: 20px;
+			}
+		}
+		.semantic-domain {
+			color: #aaa;
+		}`
+
+// Dark text on a white background.
+var lightThemeCSS = `
+		a {
+			color: #1a4fbf;
+		}
+		body {
+			background-color: #fff;
+			color: #111;
+		}
+		@media screen {
+			body {
+				border: 1px solid #d0d0d0;
+				border-radius: 20px;
+			}
+		}
+		.semantic-domain {
+			color: #666;
+		}`
+
+// Black serif text without navigation, for printing the dictionary on paper.
+var printThemeCSS = `
+		a {
+			color: inherit;
+			text-decoration: none;
+		}
+		body {
+			background-color: #fff;
+			color: #000;
+			font-family: Georgia, "Times New Roman", serif;
+			max-width: none;
+		}
+		.navbar {
+			display: none;
+		}
+		.dictionary {
+			column-count: 2;
+			column-gap: 2em;
+		}
+		.semantic-domain {
+			color: #555;
+		}`
+
+// The theme used when none is chosen.
+const DefaultTheme = "dark"
+
+// The built-in themes for HTML exports, by name.
+var themes = map[string]string{
+	"dark":  darkThemeCSS,
+	"light": lightThemeCSS,
+	"print": printThemeCSS,
+}
+
+// The names of the built-in themes, in alphabetical order.
+func ThemeNames() []string {
+	var names []string
+	for name := range themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Returned when asked for a theme that is not built in.
+type UnknownThemeError struct {
+	Name string
+}
+
+func (e *UnknownThemeError) Error() string {
+	return fmt.Sprintf("unknown theme '%s'; the themes are %s", e.Name, strings.Join(ThemeNames(), ", "))
+}
+
+// The stylesheet for a built-in theme. An empty name gives the default theme.
+func ThemeCSS(name string) (string, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return "", &UnknownThemeError{Name: name}
+	}
+	return baseCSS + theme, nil
+}
@@ -5,21 +5,82 @@ type QualifiedStrings struct {
 	Text       string   `json:"text"`
 }
 
-type Definition QualifiedStrings
 type IPA QualifiedStrings
 
+type Definition struct {
+	Qualifiers []string `json:"qualifiers,omitempty"`
+	Text       string   `json:"text"`
+	// Words to list the definition under in the reverse index, instead of the
+	// words of its text.
+	ReverseGlosses []string `json:"reverseGlosses,omitempty"`
+}
+
+// An example sentence, optionally with a translation.
+type Example struct {
+	Text        string `json:"text"`
+	Translation string `json:"translation,omitempty"`
+}
+
+// One meaning of an entry, along with the examples and notes that belong to it.
+type Sense struct {
+	Number         string        `json:"number,omitempty"`     // Sense number, as written in the source (MDF \sn).
+	Qualifiers     []string      `json:"qualifiers,omitempty"` // Labels for the whole sense, such as "archaic" or "formal".
+	Definitions    []*Definition `json:"definitions,omitempty"`
+	Glosses        []string      `json:"glosses,omitempty"` // Short translations, as opposed to full definitions.
+	Examples       []*Example    `json:"examples,omitempty"`
+	Notes          []string      `json:"notes,omitempty"`
+	SemanticDomain string        `json:"semanticDomain,omitempty"`
+}
+
+// Kinds of relations between entries.
+const (
+	RelationSeeAlso     = "see-also"
+	RelationSynonym     = "synonym"
+	RelationAntonym     = "antonym"
+	RelationDerivedFrom = "derived-from"
+	RelationCompoundOf  = "compound-of" // One of the words a compound is made of.
+	RelationVariantOf   = "variant-of"  // The main form of a variant.
+)
+
+// A link from one entry to another, such as a synonym or a "see also" reference.
+//
+// The entry being referred to is given by its ID, or by its headword and homograph
+// number. ResolveRelations fills in whichever of them is missing.
+type Relation struct {
+	Type      string `json:"type"`
+	TargetID  string `json:"targetId,omitempty"`  // ID of the entry being referred to.
+	Target    string `json:"target,omitempty"`    // Headword of the entry being referred to.
+	Homograph int    `json:"homograph,omitempty"` // Homograph number of the entry being referred to, if it has one.
+}
+
 type Entry struct {
-	Word           string        `json:"word"`
-	POS            string        `json:"partOfSpeech"`
-	Pronunciations []*IPA        `json:"pronunciations,omitempty"`
-	Definitions    []*Definition `json:"definitions"`
-	UsageNotes     []string      `json:"usageNotes,omitempty"`
-	Etymology      string        `json:"etymology,omitempty"`
-	BorrowedWord   string        `json:"borrowedWord,omitempty"`
-	LiteralMeaning string        `json:"literalMeaning,omitempty"`
+	ID             string      `json:"id,omitempty"` // Unique, and kept as the entry is edited. See AssignEntryIDs.
+	Word           string      `json:"word"`
+	Homograph      int         `json:"homograph,omitempty"` // Number distinguishing entries with the same headword.
+	POS            string      `json:"partOfSpeech"`
+	Pronunciations []*IPA      `json:"pronunciations,omitempty"`
+	Senses         []*Sense    `json:"senses"`
+	UsageNotes     []string    `json:"usageNotes,omitempty"`
+	Etymology      string      `json:"etymology,omitempty"`
+	BorrowedWord   string      `json:"borrowedWord,omitempty"`
+	LiteralMeaning string      `json:"literalMeaning,omitempty"`
+	Variants       []string    `json:"variants,omitempty"`
+	Relations      []*Relation `json:"relations,omitempty"`
+	Subentries     []*Entry    `json:"subentries,omitempty"`
+	Date           string      `json:"date,omitempty"` // Date the entry was last edited, as written in the source.
 }
 
 type Dictionary struct {
-	LanguageName string   `json:"languageName"`
-	Entries      []*Entry `json:"entries"`
+	Schema        string    `json:"$schema,omitempty"` // Location of a JSON Schema for editors to use. See JSONSchema.
+	FormatVersion int       `json:"formatVersion"`     // See CurrentFormatVersion.
+	LanguageName  string    `json:"languageName"`
+	Alphabet      *Alphabet `json:"alphabet,omitempty"` // Used to sort entries. See Collator.
+	// Whether headwords that only differ in case are different words. If not, they
+	// are numbered as homographs and put on the same page of a website.
+	CaseSensitive bool `json:"caseSensitive,omitempty"`
+	// The Unicode normalization form to keep text in, NormalizationNFC or
+	// NormalizationNFD. Text is left as it is if this is empty. See
+	// NormalizeDictionary.
+	Normalization string   `json:"normalization,omitempty"`
+	Entries       []*Entry `json:"entries"`
 }
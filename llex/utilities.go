@@ -1,18 +1,154 @@
 package llex
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 )
 
 func ReadDictionary(path string) (*Dictionary, error) {
-	jsonText, err := os.ReadFile(path)
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	var dict Dictionary
+	return ReadDictionaryFrom(file)
+}
+
+// Read a dictionary from a file in a file system, such as an embed.FS.
+func ReadDictionaryFS(fsys fs.FS, name string) (*Dictionary, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadDictionaryFrom(file)
+}
+
+// Read a dictionary from r, such as an HTTP request body. Dictionaries written by
+// older versions of llex are upgraded to the current format version.
+func ReadDictionaryFrom(r io.Reader) (*Dictionary, error) {
+	dict, _, err := MigrateDictionaryFrom(r)
+	return dict, err
+}
+
+// Copy an entry, its relations and its subentries, making sure that lists which
+// are always written are not null.
+func canonicalEntry(entry *Entry) *Entry {
+	canonical := *entry
+	if canonical.Senses == nil {
+		canonical.Senses = make([]*Sense, 0)
+	}
+
+	canonical.Relations = nil
+	for _, relation := range entry.Relations {
+		copied := *relation
+		canonical.Relations = append(canonical.Relations, &copied)
+	}
+
+	canonical.Subentries = nil
+	for _, subentry := range entry.Subentries {
+		canonical.Subentries = append(canonical.Subentries, canonicalEntry(subentry))
+	}
+
+	return &canonical
+}
+
+// Format a dictionary in the canonical llex form: the current format version,
+// entries sorted by headword in the order of the dictionary's alphabet, keys in a
+// fixed order, one field per line, and a trailing newline. Formatting the same
+// dictionary always gives the same result, so lexicons can be kept in version
+// control and compared meaningfully. The dictionary itself is not modified.
+func FormatDictionary(dict *Dictionary) ([]byte, error) {
+	canonical := *dict
+	canonical.FormatVersion = CurrentFormatVersion
+	canonical.Entries = make([]*Entry, len(dict.Entries))
+	for i, entry := range dict.Entries {
+		canonical.Entries[i] = canonicalEntry(entry)
+	}
+	AssignEntryIDs(&canonical)
+	ResolveRelations(&canonical)
+	sortEntries(canonical.Entries, dict.Collator())
+
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(&canonical); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// Write a dictionary to w in the canonical form described by FormatDictionary.
+func WriteDictionaryTo(w io.Writer, dict *Dictionary) error {
+	output, err := FormatDictionary(dict)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(output)
+	return err
+}
+
+// Write a dictionary to a file in the canonical form described by FormatDictionary.
+//
+// The dictionary is written to a temporary file which then replaces the original,
+// so that the original is never left half-written.
+func WriteDictionary(path string, dict *Dictionary) error {
+	output, err := FormatDictionary(dict)
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(path, output)
+}
+
+// Replace the contents of a file by writing a temporary file next to it and
+// renaming it over the original, keeping the original's permissions. This is how
+// WriteDictionary writes, for callers that need the formatted bytes themselves.
+func WriteFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// Clean up the temporary file if anything goes wrong. After a successful
+	// rename, this does nothing.
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Chmod(mode); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
 
-	err = json.Unmarshal(jsonText, &dict)
-	return &dict, err
+// Import an llex dictionary, so that lexicons can be passed through import like
+// any other format.
+func importJSON(r io.Reader, params *ImportParams) (*Dictionary, *ImportReport, error) {
+	dict, _, err := ReadDictionaryWithParams(r, &ReadParams{DisallowUnknownFields: params.Strict})
+	if err != nil {
+		return nil, nil, err
+	}
+	return dict, &ImportReport{}, nil
 }